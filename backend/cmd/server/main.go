@@ -2,11 +2,15 @@ package main
 
 import (
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 	"vote/internal/config"
 	"vote/internal/database"
 	"vote/internal/handlers"
 	"vote/internal/middleware"
+	"vote/internal/migrations"
 	"vote/internal/services"
 	"vote/internal/utils"
 
@@ -20,19 +24,45 @@ import (
 func main() {
 	cfg := config.Load()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(cfg, os.Args[2:])
+		return
+	}
+
 	db, err := database.Connect(cfg.DatabaseURL)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
 	defer db.Close()
 
+	if cfg.AutoMigrate {
+		if err := migrations.Up(db.DB); err != nil {
+			log.Fatal("Failed to run migrations:", err)
+		}
+	}
+
 	utils.LoadProfanityList()
 
 	auditLogger := utils.NewAuditLogger(db.DB)
-	wsHub := services.NewWebSocketHub()
-	cache := services.NewCache()
+	var broker services.Broker
+	if cfg.EventBroker == "postgres" {
+		broker, err = services.NewPostgresBroker(db.DB, cfg.DatabaseURL)
+		if err != nil {
+			log.Fatal("Failed to start event broker:", err)
+		}
+	}
+	wsHub := services.NewWebSocketHub(db.DB, broker)
+	events := services.NewEventBus()
+	events.Subscribe(wsHub.HandleEvent)
+	cache := services.NewMemoryCache(cfg.CacheSize)
 
 	go wsHub.Run()
+	secretVotes := services.NewSecretVoteBatcher(db.DB, wsHub, time.Minute)
+	go secretVotes.Run()
+	decisionEngine := services.NewDecisionEngine(db.DB, events, auditLogger, cache, secretVotes)
+	go services.NewVotingScheduler(db.DB, events, auditLogger, decisionEngine, cache, time.Minute).Run()
+	go services.NewScoreRefresher(db.DB, 5*time.Minute).Run()
+	go services.NewVoteCounterReconciler(db.DB, auditLogger, time.Hour).Run()
 
 	app := fiber.New(fiber.Config{
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
@@ -86,37 +116,62 @@ func main() {
 		return c.SendFile("../frontend/policy.html")
 	})
 
-	app.Use("/ws", func(c *fiber.Ctx) error {
-		if websocket.IsWebSocketUpgrade(c) {
-			c.Locals("allowed", true)
-			return c.Next()
-		}
-		return fiber.ErrUpgradeRequired
-	})
+	app.Use("/ws", middleware.WebSocketAuth(cfg.JWTSecret))
 
 	app.Get("/ws", websocket.New(func(c *websocket.Conn) {
-		wsHub.HandleConnection(c)
+		wsHub.HandleConnection(c, c.Locals("user_id").(string), c.Locals("role").(string), c.Locals("token_expires").(time.Time))
 	}))
 
+	voteIdentity := handlers.ParseVoteIdentity(cfg.VoteIdentity)
+
 	authHandler := handlers.NewAuthHandler(db, cfg.JWTSecret, int64(cfg.JWTExpiry.Seconds()))
-	policyHandler := handlers.NewPolicyHandler(db, auditLogger, wsHub, cache)
-	voteHandler := handlers.NewVoteHandler(db, wsHub)
-	adminHandler := handlers.NewAdminHandler(db, auditLogger)
+	policyHandler := handlers.NewPolicyHandler(db, auditLogger, wsHub, events, cache, voteIdentity)
+	voteHandler := handlers.NewVoteHandler(db, wsHub, voteIdentity)
+	adminHandler := handlers.NewAdminHandler(db, auditLogger, events, cache)
 	superuserHandler := handlers.NewSuperuserHandler(db)
-	categoryHandler := handlers.NewCategoryHandler(db)
-	analyticsHandler := handlers.NewAnalyticsHandler(db)
+	categoryHandler := handlers.NewCategoryHandler(db, cache)
+	analyticsHandler := handlers.NewAnalyticsHandler(db, cache)
 	exportHandler := handlers.NewExportHandler(db)
+	commentHandler := handlers.NewCommentHandler(db, auditLogger, events)
+	feedbackHandler := handlers.NewFeedbackHandler(db, auditLogger, cache)
+	reportHandler := handlers.NewReportHandler(db, auditLogger, cfg.ReportThreshold)
+	decisionHandler := handlers.NewDecisionHandler(db, auditLogger, decisionEngine)
+	ballotHandler := handlers.NewBallotHandler(db, auditLogger, voteIdentity)
+	revisionHandler := handlers.NewRevisionHandler(db, auditLogger, events, cache)
+	eventHandler := handlers.NewEventHandler(wsHub)
 
 	api := app.Group("/api/v1")
 
 	api.Post("/auth/code", authHandler.CodeLogin)
 	api.Get("/categories", categoryHandler.GetCategories)
+	api.Get("/events", middleware.EventStreamAuth(cfg.JWTSecret), eventHandler.StreamEvents)
 
 	protected := api.Group("", middleware.AuthRequired(cfg.JWTSecret))
 	protected.Get("/policies", policyHandler.GetPolicies)
+	protected.Get("/policies/mine", policyHandler.GetMyPolicies)
+	protected.Get("/policies/similar", policyHandler.GetSimilarPolicies)
 	protected.Get("/policies/:id", policyHandler.GetPolicy)
 	protected.Post("/policies", policyHandler.CreatePolicy)
+	protected.Put("/policies/:id", policyHandler.UpdateOwnPolicy)
+	protected.Post("/policies/:id/withdraw", policyHandler.WithdrawPolicy)
+	protected.Get("/policies/:id/timeline", policyHandler.GetPolicyTimeline)
+	protected.Get("/policies/:id/feedback", feedbackHandler.GetFeedback)
+	protected.Post("/policies/:id/feedback", feedbackHandler.PostFeedback)
+	protected.Get("/policies/:id/revisions", revisionHandler.GetRevisions)
+	protected.Get("/policies/:id/revisions/diff", revisionHandler.DiffRevisions)
 	protected.Post("/votes", voteHandler.CreateVote)
+	protected.Put("/votes", voteHandler.UpdateVote)
+	protected.Delete("/votes/:policyId", voteHandler.DeleteVote)
+	protected.Get("/comments/:policyId", commentHandler.GetComments)
+	protected.Post("/comments", commentHandler.CreateComment)
+	protected.Put("/comments/:id", commentHandler.UpdateComment)
+	protected.Delete("/comments/:id", commentHandler.DeleteComment)
+	protected.Get("/comments/:id/history", commentHandler.GetCommentHistory)
+	protected.Post("/comments/:id/report", reportHandler.ReportComment)
+	protected.Get("/ballots", ballotHandler.GetBallots)
+	protected.Get("/ballots/:id", ballotHandler.GetBallot)
+	protected.Post("/ballots/:id/vote", ballotHandler.CastBallot)
+	protected.Get("/ballots/:id/results", ballotHandler.GetResults)
 
 	admin := api.Group("/admin", middleware.AuthRequired(cfg.JWTSecret), middleware.AdminRequired())
 	admin.Get("/policies", adminHandler.GetAllPolicies)
@@ -125,13 +180,35 @@ func main() {
 	admin.Post("/policies/:id/status", adminHandler.UpdatePolicyStatus)
 	admin.Post("/policies/:id/comment", adminHandler.AddComment)
 	admin.Delete("/policies/:id", adminHandler.DeletePolicy)
+	admin.Get("/policies/:id/vote-history", voteHandler.GetVoteHistory)
+	admin.Post("/policies/:id/revisions/:revision/restore", revisionHandler.RestoreRevision)
+	admin.Post("/policies/:id/evaluate", decisionHandler.EvaluatePolicy)
+	admin.Get("/policies/:id/decisions", decisionHandler.GetDecisions)
 	admin.Post("/policies/bulk", adminHandler.BulkAction)
+	admin.Get("/policies/:id/duplicates", adminHandler.GetPolicyDuplicates)
+	admin.Post("/policies/:id/merge", adminHandler.MergePolicies)
+	admin.Post("/policies/:id/split", adminHandler.SplitPolicy)
 	admin.Post("/users", adminHandler.CreateUser)
 	admin.Get("/stats", adminHandler.GetStats)
+	admin.Get("/cache", adminHandler.GetCacheStats)
+	admin.Post("/vote-counters/reconcile", adminHandler.ReconcileVoteCounters)
 	admin.Get("/analytics", analyticsHandler.GetAnalytics)
 	admin.Get("/audit-log", adminHandler.GetAuditLog)
+	admin.Get("/comments", commentHandler.GetModerationQueue)
+	admin.Post("/comments/:id/moderate", commentHandler.ModerateComment)
+	admin.Get("/reports", reportHandler.GetReports)
+	admin.Post("/reports/:commentId/resolve", reportHandler.ResolveReports)
+	admin.Post("/reports/:commentId/dismiss", reportHandler.DismissReports)
+	admin.Get("/decision-rules", decisionHandler.GetRules)
+	admin.Put("/decision-rules", decisionHandler.SaveRule)
+	admin.Delete("/decision-rules/:id", decisionHandler.DeleteRule)
+	admin.Post("/ballots", ballotHandler.CreateBallot)
+	admin.Post("/ballots/:id/status", ballotHandler.UpdateBallotStatus)
+	admin.Delete("/ballots/:id", ballotHandler.DeleteBallot)
 	admin.Get("/export/csv", exportHandler.ExportCSV)
 	admin.Get("/export/xlsx", exportHandler.ExportExcel)
+	admin.Get("/export/ballots/:id/csv", exportHandler.ExportBallotCSV)
+	admin.Get("/export/ballots/:id/xlsx", exportHandler.ExportBallotExcel)
 
 	superuser := api.Group("/superuser", middleware.AuthRequired(cfg.JWTSecret), middleware.SuperuserRequired())
 	superuser.Get("/users", superuserHandler.GetAllUsers)
@@ -140,6 +217,26 @@ func main() {
 	superuser.Delete("/users/:id", superuserHandler.DeleteUser)
 	superuser.Post("/users/:id/toggle", superuserHandler.ToggleUserStatus)
 
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+		<-quit
+
+		// Closing the hub first ends sockets and event streams, which
+		// would otherwise hold the server open.
+		log.Println("Shutting down")
+		wsHub.Shutdown()
+		if err := app.ShutdownWithTimeout(10 * time.Second); err != nil {
+			log.Println("Shutdown error:", err)
+		}
+	}()
+
 	log.Printf("Server starting on port %s", cfg.Port)
-	log.Fatal(app.Listen(":" + cfg.Port))
+	if err := app.Listen(":" + cfg.Port); err != nil {
+		log.Fatal(err)
+	}
+	<-stopped
 }
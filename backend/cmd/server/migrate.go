@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"vote/internal/config"
+	"vote/internal/database"
+	"vote/internal/migrations"
+)
+
+// runMigrate implements `server migrate up|down [steps]|status`.
+func runMigrate(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: server migrate up|down [steps]|status")
+		os.Exit(2)
+	}
+
+	db, err := database.Connect(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	switch args[0] {
+	case "up":
+		if err := migrations.Up(db.DB); err != nil {
+			log.Fatal("Migration failed:", err)
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				log.Fatal("Steps must be a positive number")
+			}
+		}
+		if err := migrations.Down(db.DB, steps); err != nil {
+			log.Fatal("Rollback failed:", err)
+		}
+
+	case "status":
+		statuses, err := migrations.StatusOf(db.DB)
+		if err != nil {
+			log.Fatal("Failed to read migration status:", err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-32s %s\n", s.Version, s.Name, state)
+		}
+
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command %q\n", args[0])
+		os.Exit(2)
+	}
+}
@@ -3,18 +3,24 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Port           string
-	DatabaseURL    string
-	JWTSecret      string
-	JWTExpiry      time.Duration
-	AllowedOrigins string
-	Environment    string
+	Port            string
+	DatabaseURL     string
+	JWTSecret       string
+	JWTExpiry       time.Duration
+	AllowedOrigins  string
+	Environment     string
+	AutoMigrate     bool
+	ReportThreshold int
+	VoteIdentity    string
+	CacheSize       int
+	EventBroker     string
 }
 
 func Load() *Config {
@@ -23,12 +29,17 @@ func Load() *Config {
 	}
 
 	return &Config{
-		Port:           getEnv("PORT", "8080"),
-		DatabaseURL:    getEnv("DATABASE_URL", ""),
-		JWTSecret:      getEnv("JWT_SECRET", ""),
-		JWTExpiry:      parseDuration(getEnv("JWT_EXPIRY", "24h")),
-		AllowedOrigins: getEnv("ALLOWED_ORIGINS", "https://vote.prigoana.com"),
-		Environment:    getEnv("ENVIRONMENT", "production"),
+		Port:            getEnv("PORT", "8080"),
+		DatabaseURL:     getEnv("DATABASE_URL", ""),
+		JWTSecret:       getEnv("JWT_SECRET", ""),
+		JWTExpiry:       parseDuration(getEnv("JWT_EXPIRY", "24h")),
+		AllowedOrigins:  getEnv("ALLOWED_ORIGINS", "https://vote.prigoana.com"),
+		Environment:     getEnv("ENVIRONMENT", "production"),
+		AutoMigrate:     getEnv("AUTO_MIGRATE", "true") == "true",
+		ReportThreshold: parseInt(getEnv("COMMENT_REPORT_THRESHOLD", "3"), 3),
+		VoteIdentity:    getEnv("VOTE_IDENTITY", "user"),
+		CacheSize:       parseInt(getEnv("CACHE_MAX_ENTRIES", "1000"), 1000),
+		EventBroker:     getEnv("EVENT_BROKER", "local"),
 	}
 }
 
@@ -46,3 +57,11 @@ func parseDuration(s string) time.Duration {
 	}
 	return d
 }
+
+func parseInt(s string, fallback int) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return fallback
+	}
+	return n
+}
@@ -3,8 +3,11 @@ package handlers
 import (
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 	"vote/internal/database"
 	"vote/internal/models"
+	"vote/internal/services"
 	"vote/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -13,35 +16,74 @@ import (
 type AdminHandler struct {
 	DB          *database.Database
 	AuditLogger *utils.AuditLogger
+	Events      *services.EventBus
+	Cache       services.Cache
 }
 
-func NewAdminHandler(db *database.Database, auditLogger *utils.AuditLogger) *AdminHandler {
+func NewAdminHandler(db *database.Database, auditLogger *utils.AuditLogger, events *services.EventBus, cache services.Cache) *AdminHandler {
 	return &AdminHandler{
 		DB:          db,
 		AuditLogger: auditLogger,
+		Events:      events,
+		Cache:       cache,
 	}
 }
 
 func (h *AdminHandler) GetAllPolicies(c *fiber.Ctx) error {
 	status := c.Query("status")
 
+	ks := keyset{Sort: "newest", Columns: []string{"p.created_at", "p.id"}, Desc: true}
+	page, err := parsePageRequest(c, ks)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid cursor",
+		})
+	}
+
 	query := `
 		SELECT 
 			p.id, p.title, p.description, p.status, p.admin_comment,
 			p.submitted_by, p.created_at, p.category_id,
-			COALESCE(SUM(CASE WHEN v.vote_type = 'upvote' THEN 1 ELSE 0 END), 0) as upvotes,
-			COALESCE(SUM(CASE WHEN v.vote_type = 'downvote' THEN 1 ELSE 0 END), 0) as downvotes
+			p.voting_opens_at, p.voting_closes_at, p.secret_ballot,
+			p.upvotes, p.downvotes
 		FROM policies p
-		LEFT JOIN votes v ON p.id = v.policy_id
 	`
 
 	args := []interface{}{}
+	conditions := []string{}
+	argIndex := 1
 	if status != "" {
-		query += " WHERE p.status = $1"
+		conditions = append(conditions, fmt.Sprintf("p.status = $%d", argIndex))
 		args = append(args, status)
+		argIndex++
 	}
 
-	query += " GROUP BY p.id ORDER BY p.created_at DESC"
+	var total *int
+	if page.Count {
+		countQuery := `SELECT p.id FROM policies p`
+		if len(conditions) > 0 {
+			countQuery += " WHERE " + strings.Join(conditions, " AND ")
+		}
+		total, err = countRows(h.DB.DB, countQuery, args)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to fetch policies",
+			})
+		}
+	}
+
+	if page.After != nil {
+		conditions = append(conditions, ks.After(argIndex))
+		args = append(args, page.After...)
+		argIndex += len(page.After)
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += ks.OrderBy() + fmt.Sprintf(" LIMIT $%d", argIndex)
+	args = append(args, page.Limit+1)
 
 	rows, err := h.DB.DB.Query(query, args...)
 	if err != nil {
@@ -52,37 +94,61 @@ func (h *AdminHandler) GetAllPolicies(c *fiber.Ctx) error {
 	defer rows.Close()
 
 	policies := []map[string]interface{}{}
+	var nextCursor *string
+	var last models.Policy
 	for rows.Next() {
 		var p models.Policy
 		var categoryID sql.NullString
+		var opensAt, closesAt *time.Time
+		var secretBallot bool
 		err := rows.Scan(
 			&p.ID, &p.Title, &p.Description, &p.Status, &p.AdminComment,
-			&p.SubmittedBy, &p.CreatedAt, &categoryID, &p.Upvotes, &p.Downvotes,
+			&p.SubmittedBy, &p.CreatedAt, &categoryID, &opensAt, &closesAt, &secretBallot,
+			&p.Upvotes, &p.Downvotes,
 		)
 		if err != nil {
 			continue
 		}
 
+		if len(policies) == page.Limit {
+			cursor := utils.EncodeKeysetCursor(ks.Sort, timeKey(last.CreatedAt), last.ID)
+			nextCursor = &cursor
+			break
+		}
+
 		policyMap := map[string]interface{}{
-			"id":            p.ID,
-			"title":         p.Title,
-			"description":   p.Description,
-			"status":        p.Status,
-			"admin_comment": p.AdminComment,
-			"submitted_by":  p.SubmittedBy,
-			"created_at":    p.CreatedAt,
-			"upvotes":       p.Upvotes,
-			"downvotes":     p.Downvotes,
+			"id":                  p.ID,
+			"title":               p.Title,
+			"description":         p.Description,
+			"status":              p.Status,
+			"admin_comment":       p.AdminComment,
+			"submitted_by":        p.SubmittedBy,
+			"created_at":          p.CreatedAt,
+			"upvotes":             p.Upvotes,
+			"downvotes":           p.Downvotes,
+			"secret_ballot":       secretBallot,
+			"allowed_transitions": services.AllowedTransitions(p.Status),
 		}
 
 		if categoryID.Valid {
 			policyMap["category_id"] = categoryID.String
 		}
+		if opensAt != nil {
+			policyMap["voting_opens_at"] = opensAt
+		}
+		if closesAt != nil {
+			policyMap["voting_closes_at"] = closesAt
+		}
 
 		policies = append(policies, policyMap)
+		last = p
 	}
 
-	return c.JSON(policies)
+	return c.JSON(map[string]interface{}{
+		"policies":    policies,
+		"next_cursor": nextCursor,
+		"total":       total,
+	})
 }
 
 func (h *AdminHandler) GetPolicyForEdit(c *fiber.Ctx) error {
@@ -90,12 +156,16 @@ func (h *AdminHandler) GetPolicyForEdit(c *fiber.Ctx) error {
 
 	var p models.Policy
 	var categoryID sql.NullString
+	var opensAt, closesAt *time.Time
+	var secretBallot bool
 
 	err := h.DB.DB.QueryRow(`
-		SELECT id, title, description, status, admin_comment, category_id, created_at
+		SELECT id, title, description, status, admin_comment, category_id, created_at,
+			voting_opens_at, voting_closes_at, secret_ballot
 		FROM policies
 		WHERE id = $1
-	`, policyID).Scan(&p.ID, &p.Title, &p.Description, &p.Status, &p.AdminComment, &categoryID, &p.CreatedAt)
+	`, policyID).Scan(&p.ID, &p.Title, &p.Description, &p.Status, &p.AdminComment, &categoryID, &p.CreatedAt,
+		&opensAt, &closesAt, &secretBallot)
 
 	if err == sql.ErrNoRows {
 		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
@@ -110,12 +180,15 @@ func (h *AdminHandler) GetPolicyForEdit(c *fiber.Ctx) error {
 	}
 
 	response := map[string]interface{}{
-		"id":            p.ID,
-		"title":         p.Title,
-		"description":   p.Description,
-		"status":        p.Status,
-		"admin_comment": p.AdminComment,
-		"created_at":    p.CreatedAt,
+		"id":               p.ID,
+		"title":            p.Title,
+		"description":      p.Description,
+		"status":           p.Status,
+		"admin_comment":    p.AdminComment,
+		"created_at":       p.CreatedAt,
+		"voting_opens_at":  opensAt,
+		"voting_closes_at": closesAt,
+		"secret_ballot":    secretBallot,
 	}
 
 	if categoryID.Valid {
@@ -129,10 +202,16 @@ func (h *AdminHandler) UpdatePolicy(c *fiber.Ctx) error {
 	policyID := c.Params("id")
 	userID := c.Locals("user_id").(string)
 
+	// Voting window and secret ballot fields are left unchanged when
+	// omitted; set clear_voting_window to remove both window bounds.
 	var req struct {
-		Title       string  `json:"title"`
-		Description string  `json:"description"`
-		CategoryID  *string `json:"category_id"`
+		Title             string     `json:"title"`
+		Description       string     `json:"description"`
+		CategoryID        *string    `json:"category_id"`
+		VotingOpensAt     *time.Time `json:"voting_opens_at"`
+		VotingClosesAt    *time.Time `json:"voting_closes_at"`
+		ClearVotingWindow bool       `json:"clear_voting_window"`
+		SecretBallot      *bool      `json:"secret_ballot"`
 	}
 
 	if err := c.BodyParser(&req); err != nil {
@@ -159,11 +238,65 @@ func (h *AdminHandler) UpdatePolicy(c *fiber.Ctx) error {
 		})
 	}
 
-	result, err := h.DB.DB.Exec(`
+	if req.VotingOpensAt != nil && req.VotingClosesAt != nil && !req.VotingClosesAt.After(*req.VotingOpensAt) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Voting must close after it opens",
+		})
+	}
+
+	tx, err := h.DB.DB.Begin()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+	defer tx.Rollback()
+
+	var previousTitle, previousDescription string
+	var previousCategoryID *string
+	var secretBallot, hasVotes bool
+	err = tx.QueryRow(`
+		SELECT title, description, category_id, secret_ballot,
+			EXISTS(SELECT 1 FROM votes WHERE policy_id = policies.id)
+		FROM policies WHERE id = $1
+		FOR UPDATE
+	`, policyID).Scan(&previousTitle, &previousDescription, &previousCategoryID, &secretBallot, &hasVotes)
+
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "Policy not found",
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+
+	// Switching ballot mode once votes exist would leave some votes linked
+	// to users and others not, so it is only allowed before voting starts.
+	if req.SecretBallot != nil && *req.SecretBallot != secretBallot && hasVotes {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Error: "Secret ballot mode cannot change once voting has started",
+		})
+	}
+
+	_, err = tx.Exec(`
 		UPDATE policies 
-		SET title = $1, description = $2, category_id = $3
+		SET title = $1, description = $2, category_id = $3,
+			voting_opens_at = CASE WHEN $5 THEN NULL ELSE COALESCE($6, voting_opens_at) END,
+			voting_closes_at = CASE WHEN $5 THEN NULL ELSE COALESCE($7, voting_closes_at) END,
+			secret_ballot = COALESCE($8, secret_ballot)
 		WHERE id = $4
-	`, req.Title, req.Description, req.CategoryID, policyID)
+	`, req.Title, req.Description, req.CategoryID, policyID,
+		req.ClearVotingWindow, req.VotingOpensAt, req.VotingClosesAt, req.SecretBallot)
+
+	if isCheckViolation(err) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Voting must close after it opens",
+		})
+	}
 
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
@@ -171,17 +304,50 @@ func (h *AdminHandler) UpdatePolicy(c *fiber.Ctx) error {
 		})
 	}
 
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
-			Error: "Policy not found",
+	textChanged := req.Title != previousTitle || req.Description != previousDescription ||
+		!sameID(req.CategoryID, previousCategoryID)
+
+	revision := 0
+	if textChanged {
+		revision, err = services.RecordRevision(tx, policyID, userID, "edit", nil)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to update policy",
+			})
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to update policy",
 		})
 	}
 
 	if h.AuditLogger != nil {
-		h.AuditLogger.Log(userID, "update_policy", "policy", policyID, map[string]interface{}{
-			"title":       req.Title,
-			"description": req.Description,
+		details := map[string]interface{}{
+			"title":               req.Title,
+			"description":         req.Description,
+			"voting_opens_at":     req.VotingOpensAt,
+			"voting_closes_at":    req.VotingClosesAt,
+			"clear_voting_window": req.ClearVotingWindow,
+			"secret_ballot":       req.SecretBallot,
+		}
+		if textChanged {
+			details["revision"] = revision
+			details["previous_title"] = previousTitle
+			details["previous_description"] = previousDescription
+			details["previous_category_id"] = previousCategoryID
+		}
+		h.AuditLogger.Log(userID, "update_policy", "policy", policyID, details)
+	}
+
+	services.InvalidatePolicies(h.Cache)
+
+	if !sameID(req.CategoryID, previousCategoryID) {
+		h.Events.Publish(services.DomainEvent{
+			Type:               services.EventCategoryChanged,
+			PolicyID:           policyID,
+			PreviousCategoryID: derefID(previousCategoryID),
 		})
 	}
 
@@ -201,54 +367,70 @@ func (h *AdminHandler) UpdatePolicyStatus(c *fiber.Ctx) error {
 		})
 	}
 
-	validStatuses := map[string]bool{
-		"pending": true, "approved": true, "rejected": true, "uncertain": true,
-		"in_progress": true, "completed": true, "on_hold": true, "cannot_implement": true,
-	}
-
-	if !validStatuses[req.Status] {
+	if !services.IsValidStatus(req.Status) {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
 			Error: "Invalid status",
 		})
 	}
 
-	result, err := h.DB.DB.Exec(`
-		UPDATE policies 
-		SET status = $1, admin_comment = $2
-		WHERE id = $3
-	`, req.Status, req.Comment, policyID)
-
+	tx, err := h.DB.DB.Begin()
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Error: "Failed to update policy",
+			Error: "Database error",
 		})
 	}
+	defer tx.Rollback()
 
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
+	previousStatus, err := services.TransitionPolicy(tx, policyID, req.Status, &userID, req.Comment)
+	if err == services.ErrPolicyNotFound {
 		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
 			Error: "Policy not found",
 		})
 	}
+	if transitionErr, ok := err.(*services.TransitionError); ok {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Error: transitionErr.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to update policy",
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to update policy",
+		})
+	}
 
 	if h.AuditLogger != nil {
 		h.AuditLogger.Log(userID, "update_policy_status", "policy", policyID, map[string]interface{}{
-			"status":  req.Status,
-			"comment": req.Comment,
+			"previous_status": previousStatus,
+			"status":          req.Status,
+			"comment":         req.Comment,
 		})
 	}
 
+	services.InvalidatePolicies(h.Cache)
+	h.Events.PublishStatusChange(policyID, previousStatus, req.Status)
+
 	return c.JSON(models.MessageResponse{
 		Message: "Policy updated successfully",
 	})
 }
 
+// POST /api/v1/admin/policies/:id/comment
+//
+// Publishes a message from the review thread as the policy's public admin
+// comment. An empty comment unpublishes the current one; the thread keeps
+// every message that was ever published.
 func (h *AdminHandler) AddComment(c *fiber.Ctx) error {
 	policyID := c.Params("id")
 	userID := c.Locals("user_id").(string)
 
 	var req struct {
-		Comment string `json:"comment"`
+		Comment *string `json:"comment"`
 	}
 
 	if err := c.BodyParser(&req); err != nil {
@@ -257,31 +439,69 @@ func (h *AdminHandler) AddComment(c *fiber.Ctx) error {
 		})
 	}
 
-	result, err := h.DB.DB.Exec(`
-		UPDATE policies 
-		SET admin_comment = $1
-		WHERE id = $2
-	`, req.Comment, policyID)
+	comment := ""
+	if req.Comment != nil {
+		comment = strings.TrimSpace(*req.Comment)
+	}
 
+	if len(comment) > 2000 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Comment must be at most 2000 characters",
+		})
+	}
+
+	tx, err := h.DB.DB.Begin()
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Error: "Failed to add comment",
+			Error: "Database error",
 		})
 	}
+	defer tx.Rollback()
 
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
+	var exists bool
+	err = tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM policies WHERE id = $1)`, policyID).Scan(&exists)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+	if !exists {
 		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
 			Error: "Policy not found",
 		})
 	}
 
-	if h.AuditLogger != nil {
-		h.AuditLogger.Log(userID, "add_comment", "policy", policyID, map[string]interface{}{
-			"comment": req.Comment,
+	action := "unpublish_comment"
+	details := map[string]interface{}{}
+	if comment == "" {
+		_, err = tx.Exec(`UPDATE policies SET admin_comment = NULL WHERE id = $1`, policyID)
+	} else {
+		var feedback *models.PolicyFeedback
+		feedback, err = publishFeedback(tx, policyID, userID, comment)
+		if err == nil {
+			action = "add_comment"
+			details["feedback_id"] = feedback.ID
+			details["comment"] = comment
+		}
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to add comment",
 		})
 	}
 
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to add comment",
+		})
+	}
+
+	if h.AuditLogger != nil {
+		h.AuditLogger.Log(userID, action, "policy", policyID, details)
+	}
+
+	services.InvalidatePolicies(h.Cache)
+
 	return c.JSON(models.MessageResponse{
 		Message: "Comment added successfully",
 	})
@@ -291,7 +511,12 @@ func (h *AdminHandler) DeletePolicy(c *fiber.Ctx) error {
 	policyID := c.Params("id")
 	userID := c.Locals("user_id").(string)
 
-	result, err := h.DB.DB.Exec(`DELETE FROM policies WHERE id = $1`, policyID)
+	event, err := h.deletePolicy(policyID)
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "Policy not found",
+		})
+	}
 
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
@@ -299,22 +524,297 @@ func (h *AdminHandler) DeletePolicy(c *fiber.Ctx) error {
 		})
 	}
 
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
+	if h.AuditLogger != nil {
+		h.AuditLogger.Log(userID, "delete_policy", "policy", policyID, nil)
+	}
+
+	services.InvalidatePolicies(h.Cache)
+	h.Events.Publish(event)
+
+	return c.JSON(models.MessageResponse{
+		Message: "Policy deleted successfully",
+	})
+}
+
+// GET /api/v1/admin/policies/:id/duplicates
+func (h *AdminHandler) GetPolicyDuplicates(c *fiber.Ctx) error {
+	policyID := c.Params("id")
+
+	var title, description string
+	err := h.DB.DB.QueryRow(`SELECT title, description FROM policies WHERE id = $1`, policyID).Scan(&title, &description)
+	if err == sql.ErrNoRows {
 		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
 			Error: "Policy not found",
 		})
 	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+
+	statuses := append([]string{"pending"}, services.PublicStatuses...)
+	similar, err := services.FindSimilarPolicies(h.DB.DB, title, description, statuses, &policyID, nil, 10)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to check for similar policies",
+		})
+	}
+
+	return c.JSON(similar)
+}
+
+// POST /api/v1/admin/policies/:id/merge
+//
+// Merges every policy in source_ids into this one in a single transaction.
+func (h *AdminHandler) MergePolicies(c *fiber.Ctx) error {
+	targetID := c.Params("id")
+	userID := c.Locals("user_id").(string)
+
+	var req models.MergePoliciesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid request body",
+		})
+	}
+
+	sourceIDs := []string{}
+	seen := map[string]bool{}
+	for _, id := range req.SourceIDs {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			sourceIDs = append(sourceIDs, id)
+		}
+	}
+
+	if len(sourceIDs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "At least one source policy is required",
+		})
+	}
+
+	tx, err := h.DB.DB.Begin()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+	defer tx.Rollback()
+
+	results := []*models.MergeResult{}
+	for _, sourceID := range sourceIDs {
+		result, err := services.MergePolicy(tx, sourceID, targetID, userID)
+		if code, msg := mergeErrorStatus(err); code != 0 {
+			return c.Status(code).JSON(map[string]interface{}{
+				"error":     msg,
+				"source_id": sourceID,
+			})
+		}
+		results = append(results, result)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to merge policies",
+		})
+	}
+
+	h.logMerges(userID, targetID, results)
+
+	services.InvalidatePolicies(h.Cache)
+	for _, result := range results {
+		h.Events.PublishStatusChange(result.SourceID, result.PreviousStatus, "merged")
+	}
+
+	return c.JSON(map[string]interface{}{
+		"message":   "Policies merged",
+		"target_id": targetID,
+		"merged":    results,
+	})
+}
+
+// logMerges writes one audit entry for each policy and comment a merge
+// touched: every source, the target and every comment that moved.
+func (h *AdminHandler) logMerges(userID, targetID string, results []*models.MergeResult) {
+	if h.AuditLogger == nil {
+		return
+	}
+
+	sourceIDs := []string{}
+	votesMoved, commentsMoved := 0, 0
+	for _, result := range results {
+		h.AuditLogger.Log(userID, "merge_policy", "policy", result.SourceID, map[string]interface{}{
+			"target_id":       targetID,
+			"previous_status": result.PreviousStatus,
+			"votes_moved":     result.VotesMoved,
+			"votes_dropped":   result.VotesDropped,
+			"comments_moved":  result.CommentsMoved,
+			"revisions_moved": result.RevisionsMoved,
+		})
+
+		for _, commentID := range result.CommentIDs {
+			h.AuditLogger.Log(userID, "move_comment", "comment", commentID, map[string]interface{}{
+				"from_policy_id": result.SourceID,
+				"to_policy_id":   targetID,
+			})
+		}
+
+		sourceIDs = append(sourceIDs, result.SourceID)
+		votesMoved += result.VotesMoved
+		commentsMoved += result.CommentsMoved
+	}
+
+	h.AuditLogger.Log(userID, "receive_merge", "policy", targetID, map[string]interface{}{
+		"source_ids":     sourceIDs,
+		"votes_moved":    votesMoved,
+		"comments_moved": commentsMoved,
+	})
+}
+
+// POST /api/v1/admin/policies/:id/split
+//
+// Clones the policy into two or more pending parts that link back to it.
+// The original is left as it is so an admin can close or keep it.
+func (h *AdminHandler) SplitPolicy(c *fiber.Ctx) error {
+	policyID := c.Params("id")
+	userID := c.Locals("user_id").(string)
+
+	var req models.SplitPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid request body",
+		})
+	}
+
+	if len(req.Parts) < 2 || len(req.Parts) > 10 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "A split needs between 2 and 10 parts",
+		})
+	}
+
+	for i, part := range req.Parts {
+		if len(part.Title) < 10 || len(part.Title) > 200 {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: fmt.Sprintf("Part %d: title must be between 10 and 200 characters", i+1),
+			})
+		}
+
+		if len(part.Description) < 50 || len(part.Description) > 2000 {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: fmt.Sprintf("Part %d: description must be between 50 and 2000 characters", i+1),
+			})
+		}
+
+		if utils.ContainsProfanity(part.Title) || utils.ContainsProfanity(part.Description) {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: fmt.Sprintf("Part %d: content contains inappropriate language", i+1),
+			})
+		}
+	}
+
+	tx, err := h.DB.DB.Begin()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+	defer tx.Rollback()
+
+	partIDs, err := services.SplitPolicy(tx, policyID, userID, req.Parts)
+	if err == services.ErrPolicyNotFound {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "Policy not found",
+		})
+	}
+	if err == services.ErrSplitSource {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Error: err.Error(),
+		})
+	}
+	if isForeignKeyViolation(err) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Category not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to split policy",
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to split policy",
+		})
+	}
 
 	if h.AuditLogger != nil {
-		h.AuditLogger.Log(userID, "delete_policy", "policy", policyID, nil)
+		h.AuditLogger.Log(userID, "split_policy", "policy", policyID, map[string]interface{}{
+			"part_ids": partIDs,
+		})
+		for i, partID := range partIDs {
+			h.AuditLogger.Log(userID, "create_split_part", "policy", partID, map[string]interface{}{
+				"split_from": policyID,
+				"title":      req.Parts[i].Title,
+			})
+		}
 	}
 
-	return c.JSON(models.MessageResponse{
-		Message: "Policy deleted successfully",
+	services.InvalidatePolicies(h.Cache)
+	for _, partID := range partIDs {
+		h.Events.Publish(services.DomainEvent{Type: services.EventPolicyCreated, PolicyID: partID})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(map[string]interface{}{
+		"message":  "Policy split",
+		"part_ids": partIDs,
 	})
 }
 
+// mergeErrorStatus maps a MergePolicy error to a response status and
+// message, or 0 when err is nil.
+func mergeErrorStatus(err error) (int, string) {
+	if err == nil {
+		return 0, ""
+	}
+	if err == services.ErrPolicyNotFound {
+		return fiber.StatusNotFound, "Policy not found"
+	}
+	if err == services.ErrMergeSelf {
+		return fiber.StatusBadRequest, err.Error()
+	}
+	if err == services.ErrMergeTarget || err == services.ErrMergeSecretBallot || err == services.ErrMergeBallot {
+		return fiber.StatusConflict, err.Error()
+	}
+	if transitionErr, ok := err.(*services.TransitionError); ok {
+		return fiber.StatusConflict, transitionErr.Error()
+	}
+	return fiber.StatusInternalServerError, "Failed to merge policies"
+}
+
+// bulkErrorMessage is what BulkAction reports for a policy it could not
+// move, without passing on database errors.
+func bulkErrorMessage(err error) string {
+	if err == services.ErrPolicyNotFound {
+		return "Policy not found"
+	}
+	if transitionErr, ok := err.(*services.TransitionError); ok {
+		return transitionErr.Error()
+	}
+	return "Failed to update policy"
+}
+
+// bulkStatuses maps bulk action names to the status they set.
+var bulkStatuses = map[string]string{
+	"approve":          "approved",
+	"reject":           "rejected",
+	"uncertain":        "uncertain",
+	"in_progress":      "in_progress",
+	"completed":        "completed",
+	"on_hold":          "on_hold",
+	"cannot_implement": "cannot_implement",
+}
+
 func (h *AdminHandler) BulkAction(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 
@@ -333,26 +833,52 @@ func (h *AdminHandler) BulkAction(c *fiber.Ctx) error {
 
 	switch req.Action {
 	case "approve", "reject", "uncertain", "in_progress", "completed", "on_hold", "cannot_implement":
-		if req.Status == nil {
-			req.Status = &req.Action
+		status := bulkStatuses[req.Action]
+		if req.Status != nil {
+			status = *req.Status
 		}
 
+		if !services.IsValidStatus(status) {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: "Invalid status",
+			})
+		}
+
+		// Each policy moves on its own so one illegal transition does not
+		// block the rest of the selection.
+		failed := []map[string]interface{}{}
 		for _, policyID := range req.PolicyIDs {
-			_, err := h.DB.DB.Exec(`UPDATE policies SET status = $1 WHERE id = $2`, *req.Status, policyID)
+			previousStatus, err := h.transitionOne(policyID, status, userID)
 			if err != nil {
+				failed = append(failed, map[string]interface{}{
+					"policy_id": policyID,
+					"error":     bulkErrorMessage(err),
+				})
 				continue
 			}
 
 			if h.AuditLogger != nil {
 				h.AuditLogger.Log(userID, "bulk_update_status", "policy", policyID, map[string]interface{}{
-					"status": *req.Status,
+					"previous_status": previousStatus,
+					"status":          status,
 				})
 			}
+
+			h.Events.PublishStatusChange(policyID, previousStatus, status)
 		}
 
+		services.InvalidatePolicies(h.Cache)
+
+		updated := len(req.PolicyIDs) - len(failed)
+		return c.JSON(map[string]interface{}{
+			"message": fmt.Sprintf("Bulk action completed on %d policies", updated),
+			"updated": updated,
+			"failed":  failed,
+		})
+
 	case "delete":
 		for _, policyID := range req.PolicyIDs {
-			_, err := h.DB.DB.Exec(`DELETE FROM policies WHERE id = $1`, policyID)
+			event, err := h.deletePolicy(policyID)
 			if err != nil {
 				continue
 			}
@@ -360,6 +886,8 @@ func (h *AdminHandler) BulkAction(c *fiber.Ctx) error {
 			if h.AuditLogger != nil {
 				h.AuditLogger.Log(userID, "bulk_delete", "policy", policyID, nil)
 			}
+
+			h.Events.Publish(event)
 		}
 
 	case "set_category":
@@ -370,7 +898,13 @@ func (h *AdminHandler) BulkAction(c *fiber.Ctx) error {
 		}
 
 		for _, policyID := range req.PolicyIDs {
-			_, err := h.DB.DB.Exec(`UPDATE policies SET category_id = $1 WHERE id = $2`, *req.CategoryID, policyID)
+			var previousCategoryID *string
+			err := h.DB.DB.QueryRow(`
+				UPDATE policies p SET category_id = $1
+				FROM policies old
+				WHERE p.id = $2 AND old.id = p.id
+				RETURNING old.category_id
+			`, *req.CategoryID, policyID).Scan(&previousCategoryID)
 			if err != nil {
 				continue
 			}
@@ -380,6 +914,14 @@ func (h *AdminHandler) BulkAction(c *fiber.Ctx) error {
 					"category_id": *req.CategoryID,
 				})
 			}
+
+			if !sameID(req.CategoryID, previousCategoryID) {
+				h.Events.Publish(services.DomainEvent{
+					Type:               services.EventCategoryChanged,
+					PolicyID:           policyID,
+					PreviousCategoryID: derefID(previousCategoryID),
+				})
+			}
 		}
 
 	default:
@@ -388,11 +930,42 @@ func (h *AdminHandler) BulkAction(c *fiber.Ctx) error {
 		})
 	}
 
+	services.InvalidatePolicies(h.Cache)
+
 	return c.JSON(models.MessageResponse{
 		Message: fmt.Sprintf("Bulk action completed on %d policies", len(req.PolicyIDs)),
 	})
 }
 
+// deletePolicy deletes a policy and returns the event announcing it,
+// carrying the routing details that can no longer be looked up.
+func (h *AdminHandler) deletePolicy(policyID string) (services.DomainEvent, error) {
+	var categoryID, submittedBy *string
+	event := services.DomainEvent{Type: services.EventPolicyDeleted, PolicyID: policyID}
+	err := h.DB.DB.QueryRow(`
+		DELETE FROM policies WHERE id = $1
+		RETURNING category_id, submitted_by, status
+	`, policyID).Scan(&categoryID, &submittedBy, &event.Status)
+	event.CategoryID = derefID(categoryID)
+	event.AuthorID = derefID(submittedBy)
+	return event, err
+}
+
+func (h *AdminHandler) transitionOne(policyID, status, userID string) (string, error) {
+	tx, err := h.DB.DB.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	previousStatus, err := services.TransitionPolicy(tx, policyID, status, &userID, nil)
+	if err != nil {
+		return previousStatus, err
+	}
+
+	return previousStatus, tx.Commit()
+}
+
 func (h *AdminHandler) CreateUser(c *fiber.Ctx) error {
 	var req models.CreateUserRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -426,7 +999,39 @@ func (h *AdminHandler) CreateUser(c *fiber.Ctx) error {
 	})
 }
 
+// POST /api/v1/admin/vote-counters/reconcile
+//
+// Runs the vote counter check immediately instead of waiting for the
+// scheduled reconciliation.
+func (h *AdminHandler) ReconcileVoteCounters(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	repairs, err := services.ReconcileVoteCounters(h.DB.DB)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to reconcile vote counters",
+		})
+	}
+
+	if h.AuditLogger != nil {
+		for _, repair := range repairs {
+			h.AuditLogger.Log(userID, "repair_vote_counters", "policy", repair.PolicyID, repair)
+		}
+	}
+
+	services.InvalidatePolicies(h.Cache)
+
+	return c.JSON(map[string]interface{}{
+		"repaired": repairs,
+	})
+}
+
 func (h *AdminHandler) GetStats(c *fiber.Ctx) error {
+	body, generation, ok := cachedJSON(h.Cache, services.CacheKeyStats)
+	if ok {
+		return sendJSON(c, body)
+	}
+
 	var stats struct {
 		TotalPolicies   int `json:"total_policies"`
 		PendingPolicies int `json:"pending_policies"`
@@ -436,26 +1041,69 @@ func (h *AdminHandler) GetStats(c *fiber.Ctx) error {
 
 	h.DB.DB.QueryRow("SELECT COUNT(*) FROM policies").Scan(&stats.TotalPolicies)
 	h.DB.DB.QueryRow("SELECT COUNT(*) FROM policies WHERE status = 'pending'").Scan(&stats.PendingPolicies)
-	h.DB.DB.QueryRow("SELECT COUNT(*) FROM votes").Scan(&stats.TotalVotes)
+	h.DB.DB.QueryRow("SELECT COALESCE(SUM(upvotes + downvotes), 0) FROM policies").Scan(&stats.TotalVotes)
 	h.DB.DB.QueryRow("SELECT COUNT(*) FROM users WHERE role = 'student' AND is_active = true").Scan(&stats.ActiveStudents)
 
-	return c.JSON(stats)
+	body, err := cacheJSON(h.Cache, services.CacheKeyStats, generation, stats, time.Minute)
+	if err != nil {
+		return c.JSON(stats)
+	}
+	return sendJSON(c, body)
+}
+
+// GET /api/v1/admin/cache
+func (h *AdminHandler) GetCacheStats(c *fiber.Ctx) error {
+	if h.Cache == nil {
+		return c.JSON(services.CacheStats{})
+	}
+	return c.JSON(h.Cache.Stats())
 }
 
 func (h *AdminHandler) GetAuditLog(c *fiber.Ctx) error {
-	limit := c.QueryInt("limit", 50)
-	offset := c.QueryInt("offset", 0)
+	ks := keyset{Sort: "newest", Columns: []string{"al.created_at", "al.id"}, Desc: true}
+	page, err := parsePageRequest(c, ks)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid cursor",
+		})
+	}
+
+	var total *int
+	if page.Count {
+		total, err = countRows(h.DB.DB, `SELECT id FROM audit_log`, nil)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to fetch audit log",
+			})
+		}
+	}
 
-	rows, err := h.DB.DB.Query(`
+	query := `
 		SELECT 
 			al.id, al.user_id, al.action, al.entity_type, al.entity_id, 
 			al.details, al.created_at, u.login_code
 		FROM audit_log al
 		LEFT JOIN users u ON al.user_id = u.id
-		ORDER BY al.created_at DESC
-		LIMIT $1 OFFSET $2
-	`, limit, offset)
+	`
+	args := []interface{}{}
+	argIndex := 1
+	if page.After != nil {
+		query += " WHERE " + ks.After(argIndex)
+		args = append(args, page.After...)
+		argIndex += len(page.After)
+	}
+	query += ks.OrderBy() + fmt.Sprintf(" LIMIT $%d", argIndex)
+	args = append(args, page.Limit+1)
+	argIndex++
+
+	// offset is deprecated in favour of cursor and only honoured without
+	// one, so older clients keep paging until they move over.
+	if offset := c.QueryInt("offset", 0); offset > 0 && page.After == nil {
+		query += fmt.Sprintf(" OFFSET $%d", argIndex)
+		args = append(args, offset)
+	}
 
+	rows, err := h.DB.DB.Query(query, args...)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
 			Error: "Failed to fetch audit log",
@@ -464,6 +1112,8 @@ func (h *AdminHandler) GetAuditLog(c *fiber.Ctx) error {
 	defer rows.Close()
 
 	logs := []map[string]interface{}{}
+	var nextCursor *string
+	var last models.AuditLogEntry
 	for rows.Next() {
 		var log models.AuditLogEntry
 		var loginCode sql.NullString
@@ -476,6 +1126,12 @@ func (h *AdminHandler) GetAuditLog(c *fiber.Ctx) error {
 			continue
 		}
 
+		if len(logs) == page.Limit {
+			cursor := utils.EncodeKeysetCursor(ks.Sort, timeKey(last.CreatedAt), last.ID)
+			nextCursor = &cursor
+			break
+		}
+
 		logMap := map[string]interface{}{
 			"id":          log.ID,
 			"action":      log.Action,
@@ -497,7 +1153,12 @@ func (h *AdminHandler) GetAuditLog(c *fiber.Ctx) error {
 		}
 
 		logs = append(logs, logMap)
+		last = log
 	}
 
-	return c.JSON(logs)
+	return c.JSON(map[string]interface{}{
+		"entries":     logs,
+		"next_cursor": nextCursor,
+		"total":       total,
+	})
 }
@@ -1,35 +1,49 @@
 package handlers
 
 import (
+	"time"
 	"vote/internal/database"
 	"vote/internal/models"
+	"vote/internal/services"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type AnalyticsHandler struct {
-	DB *database.Database
+	DB    *database.Database
+	Cache services.Cache
 }
 
-func NewAnalyticsHandler(db *database.Database) *AnalyticsHandler {
-	return &AnalyticsHandler{DB: db}
+func NewAnalyticsHandler(db *database.Database, cache services.Cache) *AnalyticsHandler {
+	return &AnalyticsHandler{DB: db, Cache: cache}
 }
 
 // GET /api/v1/admin/analytics
 func (h *AnalyticsHandler) GetAnalytics(c *fiber.Ctx) error {
+	body, generation, ok := cachedJSON(h.Cache, services.CacheKeyAnalytics)
+	if ok {
+		return sendJSON(c, body)
+	}
+
 	var analytics models.AnalyticsResponse
 
 	// Total counts
 	h.DB.DB.QueryRow(`SELECT COUNT(*) FROM policies`).Scan(&analytics.TotalPolicies)
-	h.DB.DB.QueryRow(`SELECT COUNT(*) FROM votes`).Scan(&analytics.TotalVotes)
-	analytics.TotalComments = 0 // Set to 0 since we removed comments
+	h.DB.DB.QueryRow(`SELECT COALESCE(SUM(upvotes + downvotes), 0) FROM policies`).Scan(&analytics.TotalVotes)
+	h.DB.DB.QueryRow(`SELECT COUNT(*) FROM comments WHERE moderation_status <> 'removed'`).Scan(&analytics.TotalComments)
 
 	// Participation rate
 	var totalStudents int
 	h.DB.DB.QueryRow(`SELECT COUNT(*) FROM users WHERE role = 'student' AND is_active = true`).Scan(&totalStudents)
 
 	var activeVoters int
-	h.DB.DB.QueryRow(`SELECT COUNT(DISTINCT user_id) FROM votes`).Scan(&activeVoters)
+	h.DB.DB.QueryRow(`
+		SELECT COUNT(*) FROM (
+			SELECT user_id FROM votes WHERE user_id IS NOT NULL
+			UNION
+			SELECT user_id FROM vote_participation
+		) voters
+	`).Scan(&activeVoters)
 
 	if totalStudents > 0 {
 		analytics.ParticipationRate = float64(activeVoters) / float64(totalStudents) * 100
@@ -71,20 +85,23 @@ func (h *AnalyticsHandler) GetAnalytics(c *fiber.Ctx) error {
 	}
 	analytics.VotingTrends = trends
 
-	// Top classrooms by engagement (removed comment count)
+	// Top classrooms by engagement. Secret ballots only count participation,
+	// which is all that is stored against the user.
 	classroomRows, _ := h.DB.DB.Query(`
 		SELECT 
 			u.login_code,
-			COUNT(DISTINCT v.id) as vote_count,
-			0 as comment_count,
+			COUNT(DISTINCT v.id) + COUNT(DISTINCT vp.id) as vote_count,
+			COUNT(DISTINCT cm.id) as comment_count,
 			COUNT(DISTINCT p.id) as policy_count,
-			(COUNT(DISTINCT v.id) + COUNT(DISTINCT p.id) * 3) as engagement_score
+			(COUNT(DISTINCT v.id) + COUNT(DISTINCT vp.id) + COUNT(DISTINCT cm.id) * 2 + COUNT(DISTINCT p.id) * 3) as engagement_score
 		FROM users u
 		LEFT JOIN votes v ON u.id = v.user_id
+		LEFT JOIN vote_participation vp ON u.id = vp.user_id
+		LEFT JOIN comments cm ON u.id = cm.user_id AND cm.moderation_status <> 'removed'
 		LEFT JOIN policies p ON u.id = p.submitted_by
 		WHERE u.role = 'student' AND u.is_active = true
 		GROUP BY u.id, u.login_code
-		HAVING COUNT(DISTINCT v.id) > 0 OR COUNT(DISTINCT p.id) > 0
+		HAVING COUNT(DISTINCT v.id) > 0 OR COUNT(DISTINCT vp.id) > 0 OR COUNT(DISTINCT cm.id) > 0 OR COUNT(DISTINCT p.id) > 0
 		ORDER BY engagement_score DESC
 		LIMIT 10
 	`)
@@ -108,11 +125,10 @@ func (h *AnalyticsHandler) GetAnalytics(c *fiber.Ctx) error {
 	catRows, _ := h.DB.DB.Query(`
 		SELECT 
 			COALESCE(c.name_en, 'Uncategorized') as category_name,
-			COUNT(DISTINCT p.id) as policy_count,
-			COUNT(DISTINCT v.id) as vote_count
+			COUNT(p.id) as policy_count,
+			COALESCE(SUM(p.upvotes + p.downvotes), 0) as vote_count
 		FROM policies p
 		LEFT JOIN categories c ON p.category_id = c.id
-		LEFT JOIN votes v ON p.id = v.policy_id
 		GROUP BY c.name_en
 		ORDER BY policy_count DESC
 	`)
@@ -126,5 +142,9 @@ func (h *AnalyticsHandler) GetAnalytics(c *fiber.Ctx) error {
 	}
 	analytics.CategoryDistribution = categoryStats
 
-	return c.JSON(analytics)
+	body, err := cacheJSON(h.Cache, services.CacheKeyAnalytics, generation, analytics, 5*time.Minute)
+	if err != nil {
+		return c.JSON(analytics)
+	}
+	return sendJSON(c, body)
 }
@@ -0,0 +1,484 @@
+package handlers
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+	"vote/internal/database"
+	"vote/internal/models"
+	"vote/internal/services"
+	"vote/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var ballotMethods = map[string]bool{
+	"plurality": true,
+	"approval":  true,
+	"irv":       true,
+}
+
+type BallotHandler struct {
+	DB          *database.Database
+	AuditLogger *utils.AuditLogger
+	Identity    VoteIdentity
+}
+
+func NewBallotHandler(db *database.Database, auditLogger *utils.AuditLogger, identity VoteIdentity) *BallotHandler {
+	return &BallotHandler{
+		DB:          db,
+		AuditLogger: auditLogger,
+		Identity:    identity,
+	}
+}
+
+// GET /api/v1/ballots
+func (h *BallotHandler) GetBallots(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	role := c.Locals("role").(string)
+
+	query := `
+		SELECT b.id, b.title, b.description, b.method, b.max_choices, b.status,
+			b.closes_at, b.created_at,
+			(SELECT COUNT(*) FROM ballot_votes bv WHERE bv.ballot_id = b.id) as total_votes,
+			EXISTS(SELECT 1 FROM ballot_votes bv WHERE bv.ballot_id = b.id AND bv.user_id = $1) as has_voted
+		FROM ballots b
+	`
+	if !isModerator(role) {
+		query += ` WHERE b.status <> 'draft'`
+	}
+	query += ` ORDER BY b.created_at DESC`
+
+	rows, err := h.DB.DB.Query(query, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch ballots",
+		})
+	}
+	defer rows.Close()
+
+	ballots := []models.Ballot{}
+	for rows.Next() {
+		var b models.Ballot
+		err := rows.Scan(
+			&b.ID, &b.Title, &b.Description, &b.Method, &b.MaxChoices, &b.Status,
+			&b.ClosesAt, &b.CreatedAt, &b.TotalVotes, &b.HasVoted,
+		)
+		if err != nil {
+			continue
+		}
+		ballots = append(ballots, b)
+	}
+
+	return c.JSON(ballots)
+}
+
+// GET /api/v1/ballots/:id
+func (h *BallotHandler) GetBallot(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	role := c.Locals("role").(string)
+
+	ballot, err := h.loadVisibleBallot(c.Params("id"), role)
+	if err == services.ErrBallotNotFound {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "Ballot not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch ballot",
+		})
+	}
+
+	h.DB.DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM ballot_votes WHERE ballot_id = $1 AND user_id = $2)
+	`, ballot.ID, userID).Scan(&ballot.HasVoted)
+
+	return c.JSON(ballot)
+}
+
+// POST /api/v1/ballots/:id/vote
+func (h *BallotHandler) CastBallot(c *fiber.Ctx) error {
+	ballotID := c.Params("id")
+	userID := c.Locals("user_id").(string)
+	role := c.Locals("role").(string)
+
+	if role != "student" {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Error: "Only students can vote",
+		})
+	}
+
+	var req models.BallotVoteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid request body",
+		})
+	}
+
+	deviceFingerprint := c.Get("X-Device-Fingerprint", "")
+	if deviceFingerprint == "" && h.Identity.RequiresDevice() {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Device fingerprint required",
+		})
+	}
+
+	ballot, err := services.LoadBallot(h.DB.DB, ballotID)
+	if err == services.ErrBallotNotFound || (err == nil && ballot.Status == "draft") {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "Ballot not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+
+	if ballot.Status != "open" || (ballot.ClosesAt != nil && !ballot.ClosesAt.After(time.Now())) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Voting on this ballot has closed",
+		})
+	}
+
+	if msg := validateChoices(ballot, req.Choices); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: msg,
+		})
+	}
+
+	tx, err := h.DB.DB.Begin()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+	defer tx.Rollback()
+
+	claimsUser, claimsDevice := h.Identity.Claims()
+
+	var ballotVoteID string
+	err = tx.QueryRow(`
+		INSERT INTO ballot_votes (ballot_id, user_id, device_fingerprint, claims_user, claims_device)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5)
+		RETURNING id
+	`, ballotID, userID, deviceFingerprint, claimsUser, claimsDevice).Scan(&ballotVoteID)
+
+	if isUniqueViolation(err) {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Error: "You have already voted on this ballot",
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to record ballot",
+		})
+	}
+
+	for i, optionID := range req.Choices {
+		_, err := tx.Exec(`
+			INSERT INTO ballot_choices (ballot_vote_id, option_id, rank)
+			VALUES ($1, $2, $3)
+		`, ballotVoteID, optionID, i+1)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to record ballot",
+			})
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to record ballot",
+		})
+	}
+
+	return c.JSON(models.MessageResponse{
+		Message: "Ballot recorded",
+	})
+}
+
+// GET /api/v1/ballots/:id/results
+//
+// Results are public once a ballot is closed; admins can follow them live.
+func (h *BallotHandler) GetResults(c *fiber.Ctx) error {
+	role := c.Locals("role").(string)
+
+	ballot, err := h.loadVisibleBallot(c.Params("id"), role)
+	if err == services.ErrBallotNotFound {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "Ballot not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch ballot",
+		})
+	}
+
+	if ballot.Status != "closed" && !isModerator(role) {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Error: "Results are available once the ballot closes",
+		})
+	}
+
+	result, err := services.TallyBallot(h.DB.DB, ballot)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to tally ballot",
+		})
+	}
+
+	return c.JSON(result)
+}
+
+// POST /api/v1/admin/ballots
+func (h *BallotHandler) CreateBallot(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req models.CreateBallotRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid request body",
+		})
+	}
+
+	req.Title = strings.TrimSpace(req.Title)
+	if len(req.Title) < 5 || len(req.Title) > 200 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Title must be between 5 and 200 characters",
+		})
+	}
+
+	if !ballotMethods[req.Method] {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Method must be plurality, approval or irv",
+		})
+	}
+
+	if len(req.Options) < 2 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "A ballot needs at least two options",
+		})
+	}
+
+	if req.MaxChoices != nil && (req.Method != "approval" || *req.MaxChoices < 1 || *req.MaxChoices > len(req.Options)) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "max_choices only applies to approval ballots and must not exceed the number of options",
+		})
+	}
+
+	if req.ClosesAt != nil && !req.ClosesAt.After(time.Now()) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "closes_at must be in the future",
+		})
+	}
+
+	tx, err := h.DB.DB.Begin()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+	defer tx.Rollback()
+
+	var ballotID string
+	err = tx.QueryRow(`
+		INSERT INTO ballots (title, description, method, max_choices, closes_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, req.Title, req.Description, req.Method, req.MaxChoices, req.ClosesAt, userID).Scan(&ballotID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to create ballot",
+		})
+	}
+
+	for i, option := range req.Options {
+		label := strings.TrimSpace(option.Label)
+
+		// Options backed by a policy default to its title.
+		if option.PolicyID != nil && label == "" {
+			err := tx.QueryRow(`SELECT title FROM policies WHERE id = $1`, *option.PolicyID).Scan(&label)
+			if err == sql.ErrNoRows {
+				return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+					Error: "Option policy not found",
+				})
+			}
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+					Error: "Database error",
+				})
+			}
+		}
+
+		if label == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: "Every option needs a label or a policy",
+			})
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO ballot_options (ballot_id, policy_id, label, position)
+			VALUES ($1, $2, $3, $4)
+		`, ballotID, option.PolicyID, label, i+1)
+		if isForeignKeyViolation(err) {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: "Option policy not found",
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to create ballot",
+			})
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to create ballot",
+		})
+	}
+
+	if h.AuditLogger != nil {
+		h.AuditLogger.Log(userID, "create_ballot", "ballot", ballotID, map[string]interface{}{
+			"title":   req.Title,
+			"method":  req.Method,
+			"options": len(req.Options),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.MessageResponse{
+		ID:      ballotID,
+		Message: "Ballot created",
+	})
+}
+
+// POST /api/v1/admin/ballots/:id/status
+//
+// Ballots move from draft to open to closed and never back, so a tally can
+// never change once it has been published.
+func (h *BallotHandler) UpdateBallotStatus(c *fiber.Ctx) error {
+	ballotID := c.Params("id")
+	userID := c.Locals("user_id").(string)
+
+	var req models.BallotStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid request body",
+		})
+	}
+
+	from := map[string]string{
+		"open":   "draft",
+		"closed": "open",
+	}[req.Status]
+	if from == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Status must be open or closed",
+		})
+	}
+
+	result, err := h.DB.DB.Exec(`
+		UPDATE ballots SET status = $1 WHERE id = $2 AND status = $3
+	`, req.Status, ballotID, from)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to update ballot",
+		})
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Error: "Only a " + from + " ballot can be " + req.Status,
+		})
+	}
+
+	if h.AuditLogger != nil {
+		h.AuditLogger.Log(userID, "update_ballot_status", "ballot", ballotID, map[string]interface{}{
+			"status": req.Status,
+		})
+	}
+
+	return c.JSON(models.MessageResponse{
+		Message: "Ballot status updated",
+		Status:  req.Status,
+	})
+}
+
+// DELETE /api/v1/admin/ballots/:id
+func (h *BallotHandler) DeleteBallot(c *fiber.Ctx) error {
+	ballotID := c.Params("id")
+	userID := c.Locals("user_id").(string)
+
+	result, err := h.DB.DB.Exec(`DELETE FROM ballots WHERE id = $1`, ballotID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to delete ballot",
+		})
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "Ballot not found",
+		})
+	}
+
+	if h.AuditLogger != nil {
+		h.AuditLogger.Log(userID, "delete_ballot", "ballot", ballotID, nil)
+	}
+
+	return c.JSON(models.MessageResponse{
+		Message: "Ballot deleted",
+	})
+}
+
+// loadVisibleBallot hides draft ballots from everyone but moderators.
+func (h *BallotHandler) loadVisibleBallot(ballotID, role string) (*models.Ballot, error) {
+	ballot, err := services.LoadBallot(h.DB.DB, ballotID)
+	if err != nil {
+		return nil, err
+	}
+	if ballot.Status == "draft" && !isModerator(role) {
+		return nil, services.ErrBallotNotFound
+	}
+	return ballot, nil
+}
+
+// validateChoices checks a submitted ranking against the ballot and returns
+// an error message, or "" when the choices are valid.
+func validateChoices(ballot *models.Ballot, choices []string) string {
+	if len(choices) == 0 {
+		return "Choose at least one option"
+	}
+
+	options := map[string]bool{}
+	for _, o := range ballot.Options {
+		options[o.ID] = true
+	}
+
+	seen := map[string]bool{}
+	for _, optionID := range choices {
+		if !options[optionID] {
+			return "Unknown option for this ballot"
+		}
+		if seen[optionID] {
+			return "Each option can only be chosen once"
+		}
+		seen[optionID] = true
+	}
+
+	switch ballot.Method {
+	case "plurality":
+		if len(choices) != 1 {
+			return "Choose exactly one option"
+		}
+	case "approval":
+		if ballot.MaxChoices != nil && len(choices) > *ballot.MaxChoices {
+			return "Too many options chosen"
+		}
+	}
+
+	return ""
+}
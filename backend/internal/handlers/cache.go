@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"encoding/json"
+	"time"
+	"vote/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// cacheJSON encodes value and stores it under key unless the cache has
+// been invalidated since generation, returning the encoding so the caller
+// can send it without encoding twice.
+func cacheJSON(cache services.Cache, key string, generation uint64, value interface{}, ttl time.Duration) ([]byte, error) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if cache != nil {
+		cache.SetIfGeneration(key, body, ttl, generation)
+	}
+	return body, nil
+}
+
+// cachedJSON looks up a cached response body, tolerating handlers built
+// without a cache. On a miss it returns the generation to pass to
+// cacheJSON once the value has been loaded.
+func cachedJSON(cache services.Cache, key string) ([]byte, uint64, bool) {
+	if cache == nil {
+		return nil, 0, false
+	}
+	generation := cache.Generation(key)
+	body, ok := cache.Get(key)
+	return body, generation, ok
+}
+
+// cacheKey joins a prefix and request parameters into a key. The parts are
+// JSON encoded so that no choice of user input makes two different
+// requests share a key.
+func cacheKey(prefix string, parts ...string) string {
+	encoded, _ := json.Marshal(parts)
+	return prefix + string(encoded)
+}
+
+func sendJSON(c *fiber.Ctx, body []byte) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(body)
+}
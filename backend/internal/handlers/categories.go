@@ -1,23 +1,35 @@
 package handlers
 
 import (
+	"time"
 	"vote/internal/database"
 	"vote/internal/models"
+	"vote/internal/services"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type CategoryHandler struct {
-	DB *database.Database
+	DB    *database.Database
+	Cache services.Cache
 }
 
-func NewCategoryHandler(db *database.Database) *CategoryHandler {
-	return &CategoryHandler{DB: db}
+func NewCategoryHandler(db *database.Database, cache services.Cache) *CategoryHandler {
+	return &CategoryHandler{DB: db, Cache: cache}
 }
 
 // GET /api/v1/categories
 func (h *CategoryHandler) GetCategories(c *fiber.Ctx) error {
 	lang := c.Query("lang", "en")
+	if lang != "ro" {
+		lang = "en"
+	}
+
+	key := services.CachePrefixCategories + lang
+	body, generation, ok := cachedJSON(h.Cache, key)
+	if ok {
+		return sendJSON(c, body)
+	}
 
 	rows, err := h.DB.DB.Query(`
 		SELECT id, name_en, name_ro, slug, created_at
@@ -51,5 +63,11 @@ func (h *CategoryHandler) GetCategories(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(categories)
+	// Categories are only changed by migrations, so the TTL alone keeps
+	// this fresh enough.
+	body, err = cacheJSON(h.Cache, key, generation, categories, 10*time.Minute)
+	if err != nil {
+		return c.JSON(categories)
+	}
+	return sendJSON(c, body)
 }
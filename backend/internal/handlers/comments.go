@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"database/sql"
+	"fmt"
 	"vote/internal/database"
 	"vote/internal/models"
+	"vote/internal/services"
 	"vote/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,25 +14,106 @@ import (
 type CommentHandler struct {
 	DB          *database.Database
 	AuditLogger *utils.AuditLogger
+	Events      *services.EventBus
 }
 
-func NewCommentHandler(db *database.Database, auditLogger *utils.AuditLogger) *CommentHandler {
+func NewCommentHandler(db *database.Database, auditLogger *utils.AuditLogger, events *services.EventBus) *CommentHandler {
 	return &CommentHandler{
 		DB:          db,
 		AuditLogger: auditLogger,
+		Events:      events,
 	}
 }
 
-// GET /api/v1/comments/:policyId
+var moderationStatuses = map[string]bool{
+	"visible": true,
+	"held":    true,
+	"hidden":  true,
+	"removed": true,
+}
+
+const commentColumns = `
+	c.id, c.policy_id, c.parent_id, c.user_id, c.comment_text, c.is_flagged,
+	c.moderation_status, c.edited_at, c.created_at,
+	(SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id) as reply_count
+`
+
+func scanComment(rows *sql.Rows, comment *models.Comment) error {
+	return rows.Scan(
+		&comment.ID,
+		&comment.PolicyID,
+		&comment.ParentID,
+		&comment.UserID,
+		&comment.CommentText,
+		&comment.IsFlagged,
+		&comment.ModerationStatus,
+		&comment.EditedAt,
+		&comment.CreatedAt,
+		&comment.ReplyCount,
+	)
+}
+
+// commentOrder lists comments oldest first, as threads are read.
+var commentOrder = keyset{Sort: "oldest", Columns: []string{"c.created_at", "c.id"}}
+
+func isModerator(role string) bool {
+	return role == "admin" || role == "superuser"
+}
+
+// GET /api/v1/comments/:policyId?parent=&cursor=&limit=&count=
 func (h *CommentHandler) GetComments(c *fiber.Ctx) error {
 	policyID := c.Params("policyId")
+	parentID := c.Query("parent", "")
+	userID := c.Locals("user_id").(string)
+	role := c.Locals("role").(string)
 
-	rows, err := h.DB.DB.Query(`
-		SELECT id, policy_id, user_id, comment_text, is_flagged, created_at
-		FROM comments
-		WHERE policy_id = $1 AND is_flagged = false
-		ORDER BY created_at ASC
-	`, policyID)
+	page, err := parsePageRequest(c, commentOrder)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid cursor",
+		})
+	}
+
+	query := `SELECT ` + commentColumns + ` FROM comments c WHERE c.policy_id = $1`
+	args := []interface{}{policyID}
+	argIndex := 2
+
+	if parentID != "" {
+		query += fmt.Sprintf(` AND c.parent_id = $%d`, argIndex)
+		args = append(args, parentID)
+		argIndex++
+	} else {
+		query += ` AND c.parent_id IS NULL`
+	}
+
+	// Removed comments stay in the thread as tombstones so replies keep their
+	// context; held and hidden ones are only shown to their author.
+	if !isModerator(role) {
+		query += fmt.Sprintf(` AND (c.moderation_status IN ('visible', 'removed') OR c.user_id = $%d)`, argIndex)
+		args = append(args, userID)
+		argIndex++
+	}
+
+	var total *int
+	if page.Count {
+		total, err = countRows(h.DB.DB, query, args)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to fetch comments",
+			})
+		}
+	}
+
+	if page.After != nil {
+		query += ` AND ` + commentOrder.After(argIndex)
+		args = append(args, page.After...)
+		argIndex += len(page.After)
+	}
+
+	query += commentOrder.OrderBy() + fmt.Sprintf(` LIMIT $%d`, argIndex)
+	args = append(args, page.Limit+1)
+
+	rows, err := h.DB.DB.Query(query, args...)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
 			Error: "Failed to fetch comments",
@@ -38,24 +121,27 @@ func (h *CommentHandler) GetComments(c *fiber.Ctx) error {
 	}
 	defer rows.Close()
 
-	comments := []models.Comment{}
+	result := models.CommentPage{Comments: []models.Comment{}, Total: total}
 	for rows.Next() {
 		var comment models.Comment
-		err := rows.Scan(
-			&comment.ID,
-			&comment.PolicyID,
-			&comment.UserID,
-			&comment.CommentText,
-			&comment.IsFlagged,
-			&comment.CreatedAt,
-		)
-		if err != nil {
+		if err := scanComment(rows, &comment); err != nil {
 			continue
 		}
-		comments = append(comments, comment)
+
+		if len(result.Comments) == page.Limit {
+			last := result.Comments[page.Limit-1]
+			cursor := utils.EncodeKeysetCursor(commentOrder.Sort, timeKey(last.CreatedAt), last.ID)
+			result.NextCursor = &cursor
+			break
+		}
+
+		if comment.ModerationStatus == "removed" && !isModerator(role) {
+			comment.CommentText = ""
+		}
+		result.Comments = append(result.Comments, comment)
 	}
 
-	return c.JSON(comments)
+	return c.JSON(result)
 }
 
 // POST /api/v1/comments
@@ -92,12 +178,35 @@ func (h *CommentHandler) CreateComment(c *fiber.Ctx) error {
 		})
 	}
 
-	var commentID string
+	// Replies must stay within the same policy and cannot target removed comments
+	if req.ParentID != nil {
+		var parentPolicyID, parentStatus string
+		err := h.DB.DB.QueryRow(`
+			SELECT policy_id, moderation_status FROM comments WHERE id = $1
+		`, *req.ParentID).Scan(&parentPolicyID, &parentStatus)
+		if err == sql.ErrNoRows || (err == nil && parentPolicyID != req.PolicyID) {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+				Error: "Parent comment not found",
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Database error",
+			})
+		}
+		if parentStatus == "removed" {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: "Cannot reply to a removed comment",
+			})
+		}
+	}
+
+	var commentID, moderationStatus string
 	err = h.DB.DB.QueryRow(`
-		INSERT INTO comments (policy_id, user_id, comment_text)
-		VALUES ($1, $2, $3)
-		RETURNING id
-	`, req.PolicyID, userID, req.CommentText).Scan(&commentID)
+		INSERT INTO comments (policy_id, parent_id, user_id, comment_text)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, moderation_status
+	`, req.PolicyID, req.ParentID, userID, req.CommentText).Scan(&commentID, &moderationStatus)
 
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
@@ -108,14 +217,170 @@ func (h *CommentHandler) CreateComment(c *fiber.Ctx) error {
 	// Audit log
 	h.AuditLogger.Log(userID, "create_comment", "comment", commentID, map[string]interface{}{
 		"policy_id": req.PolicyID,
+		"parent_id": req.ParentID,
 	})
 
+	if moderationStatus == "visible" {
+		h.Events.Publish(services.DomainEvent{
+			Type:      services.EventCommentAdded,
+			PolicyID:  req.PolicyID,
+			CommentID: commentID,
+		})
+	}
+
 	return c.Status(fiber.StatusCreated).JSON(models.MessageResponse{
 		ID:      commentID,
 		Message: "Comment posted successfully",
 	})
 }
 
+// PUT /api/v1/comments/:id
+func (h *CommentHandler) UpdateComment(c *fiber.Ctx) error {
+	commentID := c.Params("id")
+	userID := c.Locals("user_id").(string)
+
+	var req models.UpdateCommentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid request body",
+		})
+	}
+
+	if len(req.CommentText) < 1 || len(req.CommentText) > 1000 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Comment must be between 1 and 1000 characters",
+		})
+	}
+
+	if utils.ContainsProfanity(req.CommentText) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Comment contains inappropriate language",
+		})
+	}
+
+	tx, err := h.DB.DB.Begin()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+	defer tx.Rollback()
+
+	var ownerID, previousText, status string
+	err = tx.QueryRow(`
+		SELECT user_id, comment_text, moderation_status FROM comments WHERE id = $1 FOR UPDATE
+	`, commentID).Scan(&ownerID, &previousText, &status)
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "Comment not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+
+	if ownerID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Error: "You can only edit your own comments",
+		})
+	}
+
+	if status == "removed" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Removed comments cannot be edited",
+		})
+	}
+
+	if previousText == req.CommentText {
+		return c.JSON(models.MessageResponse{
+			Message: "Comment unchanged",
+		})
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO comment_revisions (comment_id, comment_text, edited_by)
+		VALUES ($1, $2, $3)
+	`, commentID, previousText, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to update comment",
+		})
+	}
+
+	_, err = tx.Exec(`
+		UPDATE comments SET comment_text = $1, edited_at = NOW() WHERE id = $2
+	`, req.CommentText, commentID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to update comment",
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to update comment",
+		})
+	}
+
+	h.AuditLogger.Log(userID, "edit_comment", "comment", commentID, nil)
+
+	return c.JSON(models.MessageResponse{
+		Message: "Comment updated successfully",
+	})
+}
+
+// GET /api/v1/comments/:id/history
+func (h *CommentHandler) GetCommentHistory(c *fiber.Ctx) error {
+	commentID := c.Params("id")
+	userID := c.Locals("user_id").(string)
+	role := c.Locals("role").(string)
+
+	var ownerID string
+	err := h.DB.DB.QueryRow(`SELECT user_id FROM comments WHERE id = $1`, commentID).Scan(&ownerID)
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "Comment not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+
+	if ownerID != userID && !isModerator(role) {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Error: "You can only view the history of your own comments",
+		})
+	}
+
+	rows, err := h.DB.DB.Query(`
+		SELECT id, comment_id, comment_text, edited_by, created_at
+		FROM comment_revisions
+		WHERE comment_id = $1
+		ORDER BY created_at ASC
+	`, commentID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch comment history",
+		})
+	}
+	defer rows.Close()
+
+	revisions := []models.CommentRevision{}
+	for rows.Next() {
+		var rev models.CommentRevision
+		if err := rows.Scan(&rev.ID, &rev.CommentID, &rev.CommentText, &rev.EditedBy, &rev.CreatedAt); err != nil {
+			continue
+		}
+		revisions = append(revisions, rev)
+	}
+
+	return c.JSON(revisions)
+}
+
 // DELETE /api/v1/comments/:id
 func (h *CommentHandler) DeleteComment(c *fiber.Ctx) error {
 	commentID := c.Params("id")
@@ -130,24 +395,202 @@ func (h *CommentHandler) DeleteComment(c *fiber.Ctx) error {
 			Error: "Comment not found",
 		})
 	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
 
-	if ownerID != userID && role != "admin" && role != "superuser" {
+	if ownerID != userID && !isModerator(role) {
 		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
 			Error: "You can only delete your own comments",
 		})
 	}
 
-	_, err = h.DB.DB.Exec(`DELETE FROM comments WHERE id = $1`, commentID)
+	// Soft delete so replies keep their parent in the thread
+	_, err = h.DB.DB.Exec(`UPDATE comments SET moderation_status = 'removed' WHERE id = $1`, commentID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
 			Error: "Failed to delete comment",
 		})
 	}
 
-	// Audit log
-	h.AuditLogger.Log(userID, "delete_comment", "comment", commentID, nil)
+	if h.AuditLogger != nil {
+		h.AuditLogger.Log(userID, "delete_comment", "comment", commentID, nil)
+	}
 
 	return c.JSON(models.MessageResponse{
 		Message: "Comment deleted successfully",
 	})
 }
+
+// GET /api/v1/admin/comments?status=&cursor=&limit=&count=
+func (h *CommentHandler) GetModerationQueue(c *fiber.Ctx) error {
+	status := c.Query("status", "")
+
+	page, err := parsePageRequest(c, commentOrder)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid cursor",
+		})
+	}
+
+	query := `
+		SELECT ` + commentColumns + `, u.login_code, p.title
+		FROM comments c
+		LEFT JOIN users u ON c.user_id = u.id
+		JOIN policies p ON c.policy_id = p.id
+	`
+	args := []interface{}{}
+	argIndex := 1
+
+	if status != "" {
+		if !moderationStatuses[status] {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: "Invalid status",
+			})
+		}
+		query += fmt.Sprintf(` WHERE c.moderation_status = $%d`, argIndex)
+		args = append(args, status)
+		argIndex++
+	} else {
+		query += ` WHERE c.moderation_status IN ('held', 'hidden')`
+	}
+
+	var total *int
+	if page.Count {
+		total, err = countRows(h.DB.DB, query, args)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to fetch comments",
+			})
+		}
+	}
+
+	if page.After != nil {
+		query += ` AND ` + commentOrder.After(argIndex)
+		args = append(args, page.After...)
+		argIndex += len(page.After)
+	}
+
+	query += commentOrder.OrderBy() + fmt.Sprintf(` LIMIT $%d`, argIndex)
+	args = append(args, page.Limit+1)
+
+	rows, err := h.DB.DB.Query(query, args...)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch comments",
+		})
+	}
+	defer rows.Close()
+
+	comments := []map[string]interface{}{}
+	var last models.Comment
+	var nextCursor *string
+	for rows.Next() {
+		var comment models.Comment
+		var loginCode sql.NullString
+		var policyTitle string
+
+		err := rows.Scan(
+			&comment.ID, &comment.PolicyID, &comment.ParentID, &comment.UserID,
+			&comment.CommentText, &comment.IsFlagged, &comment.ModerationStatus,
+			&comment.EditedAt, &comment.CreatedAt, &comment.ReplyCount,
+			&loginCode, &policyTitle,
+		)
+		if err != nil {
+			continue
+		}
+
+		if len(comments) == page.Limit {
+			next := utils.EncodeKeysetCursor(commentOrder.Sort, timeKey(last.CreatedAt), last.ID)
+			nextCursor = &next
+			break
+		}
+
+		commentMap := map[string]interface{}{
+			"id":                comment.ID,
+			"policy_id":         comment.PolicyID,
+			"policy_title":      policyTitle,
+			"parent_id":         comment.ParentID,
+			"user_id":           comment.UserID,
+			"comment_text":      comment.CommentText,
+			"moderation_status": comment.ModerationStatus,
+			"reply_count":       comment.ReplyCount,
+			"edited_at":         comment.EditedAt,
+			"created_at":        comment.CreatedAt,
+		}
+		if loginCode.Valid {
+			commentMap["user_code"] = loginCode.String
+		}
+
+		comments = append(comments, commentMap)
+		last = comment
+	}
+
+	return c.JSON(fiber.Map{
+		"comments":    comments,
+		"next_cursor": nextCursor,
+		"total":       total,
+	})
+}
+
+// POST /api/v1/admin/comments/:id/moderate
+func (h *CommentHandler) ModerateComment(c *fiber.Ctx) error {
+	commentID := c.Params("id")
+	userID := c.Locals("user_id").(string)
+
+	var req models.ModerateCommentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid request body",
+		})
+	}
+
+	if !moderationStatuses[req.Status] {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Status must be visible, held, hidden or removed",
+		})
+	}
+
+	var policyID, previousStatus string
+	err := h.DB.DB.QueryRow(`
+		UPDATE comments c SET moderation_status = $1
+		FROM comments old
+		WHERE c.id = $2 AND old.id = c.id
+		RETURNING c.policy_id, old.moderation_status
+	`, req.Status, commentID).Scan(&policyID, &previousStatus)
+
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "Comment not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to moderate comment",
+		})
+	}
+
+	if h.AuditLogger != nil {
+		h.AuditLogger.Log(userID, "moderate_comment", "comment", commentID, map[string]interface{}{
+			"from":   previousStatus,
+			"to":     req.Status,
+			"reason": req.Reason,
+		})
+	}
+
+	// A held comment released by a moderator appears to readers now.
+	if req.Status == "visible" && previousStatus != "visible" {
+		h.Events.Publish(services.DomainEvent{
+			Type:      services.EventCommentAdded,
+			PolicyID:  policyID,
+			CommentID: commentID,
+		})
+	}
+
+	return c.JSON(models.MessageResponse{
+		Message: "Comment moderated successfully",
+		Status:  req.Status,
+	})
+}
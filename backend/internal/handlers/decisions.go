@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"vote/internal/database"
+	"vote/internal/models"
+	"vote/internal/services"
+	"vote/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DecisionHandler struct {
+	DB          *database.Database
+	AuditLogger *utils.AuditLogger
+	Engine      *services.DecisionEngine
+}
+
+func NewDecisionHandler(db *database.Database, auditLogger *utils.AuditLogger, engine *services.DecisionEngine) *DecisionHandler {
+	return &DecisionHandler{
+		DB:          db,
+		AuditLogger: auditLogger,
+		Engine:      engine,
+	}
+}
+
+// GET /api/v1/admin/decision-rules
+func (h *DecisionHandler) GetRules(c *fiber.Ctx) error {
+	rows, err := h.DB.DB.Query(`
+		SELECT id, category_id, policy_id, min_turnout_percent, min_approval_ratio,
+			min_net_votes, mode, created_at, updated_at
+		FROM decision_rules
+		ORDER BY (policy_id IS NOT NULL), (category_id IS NOT NULL), created_at
+	`)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch decision rules",
+		})
+	}
+	defer rows.Close()
+
+	rules := []models.DecisionRule{}
+	for rows.Next() {
+		var r models.DecisionRule
+		err := rows.Scan(
+			&r.ID, &r.CategoryID, &r.PolicyID, &r.MinTurnoutPercent, &r.MinApprovalRatio,
+			&r.MinNetVotes, &r.Mode, &r.CreatedAt, &r.UpdatedAt,
+		)
+		if err != nil {
+			continue
+		}
+		rules = append(rules, r)
+	}
+
+	return c.JSON(rules)
+}
+
+// PUT /api/v1/admin/decision-rules
+//
+// Creates or replaces the rule for the scope given by category_id or
+// policy_id; with neither set the global rule is replaced.
+func (h *DecisionHandler) SaveRule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req models.DecisionRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid request body",
+		})
+	}
+
+	if req.Mode == "" {
+		req.Mode = "recommend"
+	}
+	if req.Mode != "recommend" && req.Mode != "automatic" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Mode must be recommend or automatic",
+		})
+	}
+
+	if req.CategoryID != nil && req.PolicyID != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "A rule applies to a category or a policy, not both",
+		})
+	}
+
+	if req.MinTurnoutPercent != nil && (*req.MinTurnoutPercent < 0 || *req.MinTurnoutPercent > 100) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Minimum turnout must be between 0 and 100 percent",
+		})
+	}
+
+	if req.MinApprovalRatio != nil && (*req.MinApprovalRatio < 0 || *req.MinApprovalRatio > 1) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Minimum approval ratio must be between 0 and 1",
+		})
+	}
+
+	tx, err := h.DB.DB.Begin()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+	defer tx.Rollback()
+
+	// Each scope has its own partial unique index, so replacing a rule is a
+	// delete of the old one followed by an insert.
+	_, err = tx.Exec(`
+		DELETE FROM decision_rules
+		WHERE category_id IS NOT DISTINCT FROM $1 AND policy_id IS NOT DISTINCT FROM $2
+	`, req.CategoryID, req.PolicyID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to save decision rule",
+		})
+	}
+
+	var rule models.DecisionRule
+	err = tx.QueryRow(`
+		INSERT INTO decision_rules (category_id, policy_id, min_turnout_percent, min_approval_ratio, min_net_votes, mode)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, category_id, policy_id, min_turnout_percent, min_approval_ratio,
+			min_net_votes, mode, created_at, updated_at
+	`, req.CategoryID, req.PolicyID, req.MinTurnoutPercent, req.MinApprovalRatio, req.MinNetVotes, req.Mode).Scan(
+		&rule.ID, &rule.CategoryID, &rule.PolicyID, &rule.MinTurnoutPercent, &rule.MinApprovalRatio,
+		&rule.MinNetVotes, &rule.Mode, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+	if isForeignKeyViolation(err) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Category or policy not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to save decision rule",
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to save decision rule",
+		})
+	}
+
+	if h.AuditLogger != nil {
+		h.AuditLogger.Log(userID, "save_decision_rule", "decision_rule", rule.ID, map[string]interface{}{
+			"category_id":         rule.CategoryID,
+			"policy_id":           rule.PolicyID,
+			"min_turnout_percent": rule.MinTurnoutPercent,
+			"min_approval_ratio":  rule.MinApprovalRatio,
+			"min_net_votes":       rule.MinNetVotes,
+			"mode":                rule.Mode,
+		})
+	}
+
+	return c.JSON(rule)
+}
+
+// DELETE /api/v1/admin/decision-rules/:id
+func (h *DecisionHandler) DeleteRule(c *fiber.Ctx) error {
+	ruleID := c.Params("id")
+	userID := c.Locals("user_id").(string)
+
+	result, err := h.DB.DB.Exec(`DELETE FROM decision_rules WHERE id = $1`, ruleID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to delete decision rule",
+		})
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "Decision rule not found",
+		})
+	}
+
+	if h.AuditLogger != nil {
+		h.AuditLogger.Log(userID, "delete_decision_rule", "decision_rule", ruleID, nil)
+	}
+
+	return c.JSON(models.MessageResponse{
+		Message: "Decision rule deleted",
+	})
+}
+
+// POST /api/v1/admin/policies/:id/evaluate
+//
+// Evaluates the applicable rule now. The outcome is applied when the rule is
+// automatic or the request sets apply.
+func (h *DecisionHandler) EvaluatePolicy(c *fiber.Ctx) error {
+	policyID := c.Params("id")
+	userID := c.Locals("user_id").(string)
+
+	var req models.EvaluatePolicyRequest
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid request body",
+		})
+	}
+
+	decision, err := h.Engine.Evaluate(policyID, &userID, req.Apply)
+	if err == services.ErrPolicyNotFound {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "Policy not found",
+		})
+	}
+	if err == services.ErrNoDecisionRule {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "No decision rule applies to this policy",
+		})
+	}
+	if transitionErr, ok := err.(*services.TransitionError); ok {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Error: transitionErr.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to evaluate policy",
+		})
+	}
+
+	return c.JSON(decision)
+}
+
+// GET /api/v1/admin/policies/:id/decisions
+func (h *DecisionHandler) GetDecisions(c *fiber.Ctx) error {
+	policyID := c.Params("id")
+
+	rows, err := h.DB.DB.Query(`
+		SELECT id, policy_id, rule_id, rule_snapshot, outcome, recommended_status, applied,
+			upvotes, downvotes, voters, active_students, turnout_percent,
+			approval_ratio, net_votes, decided_by, created_at
+		FROM policy_decisions
+		WHERE policy_id = $1
+		ORDER BY created_at DESC
+	`, policyID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch decisions",
+		})
+	}
+	defer rows.Close()
+
+	decisions := []models.PolicyDecision{}
+	for rows.Next() {
+		var d models.PolicyDecision
+		var snapshot []byte
+		err := rows.Scan(
+			&d.ID, &d.PolicyID, &d.RuleID, &snapshot, &d.Outcome, &d.RecommendedStatus, &d.Applied,
+			&d.Upvotes, &d.Downvotes, &d.Voters, &d.ActiveStudents, &d.TurnoutPercent,
+			&d.ApprovalRatio, &d.NetVotes, &d.DecidedBy, &d.CreatedAt,
+		)
+		if err != nil {
+			continue
+		}
+		d.Rule = snapshot
+		decisions = append(decisions, d)
+	}
+
+	return c.JSON(decisions)
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"time"
+	"vote/internal/models"
+	"vote/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type EventHandler struct {
+	WSHub *services.WebSocketHub
+}
+
+func NewEventHandler(wsHub *services.WebSocketHub) *EventHandler {
+	return &EventHandler{WSHub: wsHub}
+}
+
+// GET /api/v1/events?topics=policy:<id>,mine
+// Streams the same messages as the WebSocket for clients that cannot
+// upgrade. Browsers resend Last-Event-ID when they reconnect on their own;
+// a page that reopens the stream to change topics passes last_event_id.
+func (h *EventHandler) StreamEvents(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	role := c.Locals("role").(string)
+	expires := c.Locals("token_expires").(time.Time)
+
+	var topics []string
+	for _, topic := range strings.Split(c.Query("topics"), ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			topics = append(topics, topic)
+		}
+	}
+
+	lastEventID := c.Get("Last-Event-ID", c.Query("last_event_id"))
+
+	client, err := h.WSHub.NewEventClient(userID, role, expires, topics, lastEventID)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		switch {
+		case errors.Is(err, services.ErrTopicForbidden):
+			status = fiber.StatusForbidden
+		case errors.Is(err, services.ErrTopicUnavailable):
+			status = fiber.StatusInternalServerError
+		}
+		return c.Status(status).JSON(models.ErrorResponse{
+			Error: err.Error(),
+		})
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		h.WSHub.StreamEvents(w, client)
+	})
+	return nil
+}
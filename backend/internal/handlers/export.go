@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 	"vote/internal/database"
+	"vote/internal/models"
+	"vote/internal/services"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/xuri/excelize/v2"
@@ -28,11 +30,9 @@ func (h *ExportHandler) ExportCSV(c *fiber.Ctx) error {
 		SELECT 
 			p.id, p.title, p.description, p.status, p.created_at,
 			COALESCE(c.name_en, 'N/A') as category,
-			COALESCE(SUM(CASE WHEN v.vote_type = 'upvote' THEN 1 ELSE 0 END), 0) as upvotes,
-			COALESCE(SUM(CASE WHEN v.vote_type = 'downvote' THEN 1 ELSE 0 END), 0) as downvotes
+			p.upvotes, p.downvotes
 		FROM policies p
 		LEFT JOIN categories c ON p.category_id = c.id
-		LEFT JOIN votes v ON p.id = v.policy_id
 	`
 
 	args := []interface{}{}
@@ -46,7 +46,7 @@ func (h *ExportHandler) ExportCSV(c *fiber.Ctx) error {
 		query += fmt.Sprintf(` WHERE p.id IN (%s)`, strings.Join(placeholders, ","))
 	}
 
-	query += ` GROUP BY p.id, c.name_en ORDER BY p.created_at DESC`
+	query += ` ORDER BY p.created_at DESC`
 
 	rows, err := h.DB.DB.Query(query, args...)
 	if err != nil {
@@ -98,12 +98,10 @@ func (h *ExportHandler) ExportExcel(c *fiber.Ctx) error {
 		SELECT 
 			p.id, p.title, p.description, p.status, p.created_at,
 			COALESCE(c.name_en, 'N/A') as category,
-			COALESCE(SUM(CASE WHEN v.vote_type = 'upvote' THEN 1 ELSE 0 END), 0) as upvotes,
-			COALESCE(SUM(CASE WHEN v.vote_type = 'downvote' THEN 1 ELSE 0 END), 0) as downvotes,
+			p.upvotes, p.downvotes,
 			p.admin_comment
 		FROM policies p
 		LEFT JOIN categories c ON p.category_id = c.id
-		LEFT JOIN votes v ON p.id = v.policy_id
 	`
 
 	args := []interface{}{}
@@ -117,7 +115,7 @@ func (h *ExportHandler) ExportExcel(c *fiber.Ctx) error {
 		query += fmt.Sprintf(` WHERE p.id IN (%s)`, strings.Join(placeholders, ","))
 	}
 
-	query += ` GROUP BY p.id, c.name_en ORDER BY p.created_at DESC`
+	query += ` ORDER BY p.created_at DESC`
 
 	rows, err := h.DB.DB.Query(query, args...)
 	if err != nil {
@@ -196,3 +194,150 @@ func (h *ExportHandler) ExportExcel(c *fiber.Ctx) error {
 
 	return c.Send(buffer.Bytes())
 }
+
+// ballotRoundRows flattens a tally into one row per option per round.
+func ballotRoundRows(result *models.BallotResult) [][]string {
+	winners := map[string]bool{}
+	for _, id := range result.Winners {
+		winners[id] = true
+	}
+
+	rows := [][]string{}
+	for i, round := range result.Rounds {
+		eliminated := map[string]bool{}
+		for _, id := range round.Eliminated {
+			eliminated[id] = true
+		}
+
+		for _, count := range round.Counts {
+			outcome := ""
+			if eliminated[count.OptionID] {
+				outcome = "eliminated"
+			} else if i == len(result.Rounds)-1 && winners[count.OptionID] {
+				outcome = "winner"
+			}
+
+			rows = append(rows, []string{
+				strconv.Itoa(round.Round),
+				count.Label,
+				strconv.Itoa(count.Votes),
+				strconv.Itoa(round.Exhausted),
+				outcome,
+			})
+		}
+	}
+	return rows
+}
+
+// tallyBallot loads and tallies a ballot, returning a non-zero status code and
+// message when that fails.
+func (h *ExportHandler) tallyBallot(ballotID string) (*models.Ballot, *models.BallotResult, int, string) {
+	ballot, err := services.LoadBallot(h.DB.DB, ballotID)
+	if err == services.ErrBallotNotFound {
+		return nil, nil, fiber.StatusNotFound, "Ballot not found"
+	}
+	if err != nil {
+		return nil, nil, fiber.StatusInternalServerError, "Failed to fetch data"
+	}
+
+	result, err := services.TallyBallot(h.DB.DB, ballot)
+	if err != nil {
+		return nil, nil, fiber.StatusInternalServerError, "Failed to tally ballot"
+	}
+
+	return ballot, result, 0, ""
+}
+
+// GET /api/v1/admin/export/ballots/:id/csv
+func (h *ExportHandler) ExportBallotCSV(c *fiber.Ctx) error {
+	ballot, result, code, msg := h.tallyBallot(c.Params("id"))
+	if code != 0 {
+		return c.Status(code).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	var csvData strings.Builder
+	writer := csv.NewWriter(&csvData)
+
+	writer.Write([]string{"Ballot", ballot.Title})
+	writer.Write([]string{"Method", result.Method})
+	writer.Write([]string{"Ballots Cast", strconv.Itoa(result.TotalBallots)})
+	writer.Write([]string{})
+	writer.Write([]string{"Round", "Option", "Votes", "Exhausted", "Outcome"})
+	for _, row := range ballotRoundRows(result) {
+		writer.Write(row)
+	}
+
+	writer.Flush()
+
+	c.Set("Content-Type", "text/csv")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=ballot_%s_%s.csv", ballot.ID, time.Now().Format("2006-01-02")))
+
+	return c.SendString(csvData.String())
+}
+
+// GET /api/v1/admin/export/ballots/:id/xlsx
+func (h *ExportHandler) ExportBallotExcel(c *fiber.Ctx) error {
+	ballot, result, code, msg := h.tallyBallot(c.Params("id"))
+	if code != 0 {
+		return c.Status(code).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	f := excelize.NewFile()
+	sheet := "Results"
+	index, _ := f.NewSheet(sheet)
+	f.SetActiveSheet(index)
+	f.DeleteSheet("Sheet1")
+
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"#E5E7EB"}},
+	})
+
+	f.SetCellValue(sheet, "A1", "Ballot")
+	f.SetCellValue(sheet, "B1", ballot.Title)
+	f.SetCellValue(sheet, "A2", "Method")
+	f.SetCellValue(sheet, "B2", result.Method)
+	f.SetCellValue(sheet, "A3", "Ballots Cast")
+	f.SetCellValue(sheet, "B3", result.TotalBallots)
+
+	headers := []string{"Round", "Option", "Votes", "Exhausted", "Outcome"}
+	for i, header := range headers {
+		cell := fmt.Sprintf("%s5", string(rune('A'+i)))
+		f.SetCellValue(sheet, cell, header)
+		f.SetCellStyle(sheet, cell, cell, headerStyle)
+	}
+
+	f.SetColWidth(sheet, "A", "A", 12)
+	f.SetColWidth(sheet, "B", "B", 40)
+	f.SetColWidth(sheet, "C", "D", 10)
+	f.SetColWidth(sheet, "E", "E", 15)
+
+	rowNum := 6
+	for _, row := range ballotRoundRows(result) {
+		for i, value := range row {
+			cell := fmt.Sprintf("%s%d", string(rune('A'+i)), rowNum)
+			if n, err := strconv.Atoi(value); err == nil && i != 1 {
+				f.SetCellValue(sheet, cell, n)
+			} else {
+				f.SetCellValue(sheet, cell, value)
+			}
+		}
+		rowNum++
+	}
+
+	buffer, err := f.WriteToBuffer()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate file",
+		})
+	}
+
+	c.Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=ballot_%s_%s.xlsx", ballot.ID, time.Now().Format("2006-01-02")))
+
+	return c.Send(buffer.Bytes())
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"database/sql"
+	"strings"
+	"vote/internal/database"
+	"vote/internal/models"
+	"vote/internal/services"
+	"vote/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type FeedbackHandler struct {
+	DB          *database.Database
+	AuditLogger *utils.AuditLogger
+	Cache       services.Cache
+}
+
+func NewFeedbackHandler(db *database.Database, auditLogger *utils.AuditLogger, cache services.Cache) *FeedbackHandler {
+	return &FeedbackHandler{
+		DB:          db,
+		AuditLogger: auditLogger,
+		Cache:       cache,
+	}
+}
+
+// feedbackKinds lists the kinds each side of the thread may post. Authors
+// resubmit by editing their policy, which adds the resubmitted entry.
+var feedbackKinds = map[string]bool{
+	"message":           true,
+	"changes_requested": true,
+	"published":         true,
+}
+
+// GET /api/v1/policies/:id/feedback
+func (h *FeedbackHandler) GetFeedback(c *fiber.Ctx) error {
+	policyID := c.Params("id")
+	userID := c.Locals("user_id").(string)
+	role := c.Locals("role").(string)
+
+	code, msg := h.checkThreadAccess(policyID, userID, role)
+	if code != 0 {
+		return c.Status(code).JSON(models.ErrorResponse{Error: msg})
+	}
+
+	rows, err := h.DB.DB.Query(`
+		SELECT f.id, f.policy_id, f.author_id, u.role, f.kind, f.body, f.created_at
+		FROM policy_feedback f
+		LEFT JOIN users u ON f.author_id = u.id
+		WHERE f.policy_id = $1
+		ORDER BY f.created_at ASC
+	`, policyID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch feedback",
+		})
+	}
+	defer rows.Close()
+
+	thread := []models.PolicyFeedback{}
+	for rows.Next() {
+		var f models.PolicyFeedback
+		err := rows.Scan(&f.ID, &f.PolicyID, &f.AuthorID, &f.AuthorRole, &f.Kind, &f.Body, &f.CreatedAt)
+		if err != nil {
+			continue
+		}
+
+		f.Mine = f.AuthorID != nil && *f.AuthorID == userID
+		if !isModerator(role) {
+			f.AuthorID = nil
+		}
+		thread = append(thread, f)
+	}
+
+	return c.JSON(thread)
+}
+
+// POST /api/v1/policies/:id/feedback
+//
+// Authors may only post messages. Reviewers may also request changes to a
+// pending policy or publish a message as the policy's public admin comment.
+func (h *FeedbackHandler) PostFeedback(c *fiber.Ctx) error {
+	policyID := c.Params("id")
+	userID := c.Locals("user_id").(string)
+	role := c.Locals("role").(string)
+
+	var req models.FeedbackRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid request body",
+		})
+	}
+
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Kind == "" {
+		req.Kind = "message"
+	}
+
+	if !feedbackKinds[req.Kind] {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid feedback kind",
+		})
+	}
+
+	if req.Kind != "message" && !isModerator(role) {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Error: "Only reviewers can request changes or publish comments",
+		})
+	}
+
+	if len(req.Body) == 0 || len(req.Body) > 2000 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Message must be between 1 and 2000 characters",
+		})
+	}
+
+	if utils.ContainsProfanity(req.Body) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Content contains inappropriate language",
+		})
+	}
+
+	code, msg := h.checkThreadAccess(policyID, userID, role)
+	if code != 0 {
+		return c.Status(code).JSON(models.ErrorResponse{Error: msg})
+	}
+
+	tx, err := h.DB.DB.Begin()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+	defer tx.Rollback()
+
+	if req.Kind == "changes_requested" {
+		var status string
+		err := tx.QueryRow(`SELECT status FROM policies WHERE id = $1 FOR UPDATE`, policyID).Scan(&status)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Database error",
+			})
+		}
+		if status != "pending" {
+			return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+				Error: "Changes can only be requested while a policy is pending",
+			})
+		}
+	}
+
+	var feedback *models.PolicyFeedback
+	if req.Kind == "published" {
+		feedback, err = publishFeedback(tx, policyID, userID, req.Body)
+	} else {
+		feedback, err = addFeedback(tx, policyID, userID, req.Kind, req.Body)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to post feedback",
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to post feedback",
+		})
+	}
+
+	if h.AuditLogger != nil {
+		h.AuditLogger.Log(userID, "post_feedback", "policy", policyID, map[string]interface{}{
+			"feedback_id": feedback.ID,
+			"kind":        feedback.Kind,
+			"body":        feedback.Body,
+		})
+	}
+
+	// A published message replaces the admin comment shown in lists.
+	if feedback.Kind == "published" {
+		services.InvalidatePolicies(h.Cache)
+	}
+
+	feedback.Mine = true
+	return c.Status(fiber.StatusCreated).JSON(feedback)
+}
+
+// checkThreadAccess limits a review thread to reviewers and the policy's
+// author. It returns a non-zero status code and message otherwise.
+func (h *FeedbackHandler) checkThreadAccess(policyID, userID, role string) (int, string) {
+	var submittedBy *string
+	err := h.DB.DB.QueryRow(`SELECT submitted_by FROM policies WHERE id = $1`, policyID).Scan(&submittedBy)
+	if err == sql.ErrNoRows {
+		return fiber.StatusNotFound, "Policy not found"
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, "Database error"
+	}
+
+	if !isModerator(role) && (submittedBy == nil || *submittedBy != userID) {
+		return fiber.StatusForbidden, "Only reviewers and the author can see this thread"
+	}
+
+	return 0, ""
+}
+
+func addFeedback(tx *sql.Tx, policyID, authorID, kind, body string) (*models.PolicyFeedback, error) {
+	f := models.PolicyFeedback{PolicyID: policyID, AuthorID: &authorID, Kind: kind, Body: body}
+	err := tx.QueryRow(`
+		INSERT INTO policy_feedback (policy_id, author_id, kind, body)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, policyID, authorID, kind, body).Scan(&f.ID, &f.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// publishFeedback adds a published message to the thread and makes it the
+// policy's public admin comment, replacing any earlier one.
+func publishFeedback(tx *sql.Tx, policyID, authorID, body string) (*models.PolicyFeedback, error) {
+	f, err := addFeedback(tx, policyID, authorID, "published", body)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`UPDATE policies SET admin_comment = $1 WHERE id = $2`, body, policyID)
+	return f, err
+}
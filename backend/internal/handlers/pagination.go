@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"vote/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 100
+)
+
+// keyset describes how a list is ordered for cursor pagination. Columns are
+// compared as a row, so they must all sort in the same direction and the
+// last one must be unique.
+type keyset struct {
+	Sort    string
+	Columns []string
+	Desc    bool
+}
+
+// OrderBy returns the ORDER BY clause for the keyset.
+func (k keyset) OrderBy() string {
+	dir := " ASC"
+	if k.Desc {
+		dir = " DESC"
+	}
+	return ` ORDER BY ` + strings.Join(k.Columns, dir+", ") + dir
+}
+
+// After returns a condition selecting rows past the cursor, whose keys are
+// bound starting at placeholder $argIndex.
+func (k keyset) After(argIndex int) string {
+	op := ">"
+	if k.Desc {
+		op = "<"
+	}
+
+	placeholders := make([]string, len(k.Columns))
+	for i := range k.Columns {
+		placeholders[i] = fmt.Sprintf("$%d", argIndex+i)
+	}
+
+	return fmt.Sprintf(`(%s) %s (%s)`, strings.Join(k.Columns, ", "), op, strings.Join(placeholders, ", "))
+}
+
+// pageRequest holds the limit, cursor and count query parameters shared by
+// paginated list endpoints.
+type pageRequest struct {
+	Limit int
+	After []interface{}
+	Count bool
+}
+
+// parsePageRequest reads ?limit=&cursor=&count= for a list ordered by ks.
+func parsePageRequest(c *fiber.Ctx, ks keyset) (pageRequest, error) {
+	page := pageRequest{
+		Limit: c.QueryInt("limit", defaultPageSize),
+		Count: c.QueryBool("count", false),
+	}
+
+	if page.Limit < 1 || page.Limit > maxPageSize {
+		page.Limit = defaultPageSize
+	}
+
+	if cursor := c.Query("cursor", ""); cursor != "" {
+		keys, err := utils.DecodeKeysetCursor(cursor, ks.Sort, len(ks.Columns))
+		if err != nil {
+			return page, err
+		}
+		for _, key := range keys {
+			page.After = append(page.After, key)
+		}
+	}
+
+	return page, nil
+}
+
+// timeKey and numberKey format sort keys for EncodeKeysetCursor.
+func timeKey(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func numberKey(n float64) string {
+	return strconv.FormatFloat(n, 'g', -1, 64)
+}
+
+// countRows counts the rows query returns, for the optional total of a
+// paginated list.
+func countRows(db *sql.DB, query string, args []interface{}) (*int, error) {
+	var total int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM (`+query+`) counted`, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+	return &total, nil
+}
@@ -2,128 +2,279 @@ package handlers
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 	"vote/internal/database"
 	"vote/internal/models"
 	"vote/internal/services"
 	"vote/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
 )
 
 type PolicyHandler struct {
-	DB          *database.Database
-	AuditLogger *utils.AuditLogger
-	WSHub       *services.WebSocketHub
-	Cache       *services.Cache
+	DB           *database.Database
+	AuditLogger  *utils.AuditLogger
+	WSHub        *services.WebSocketHub
+	Events       *services.EventBus
+	Cache        services.Cache
+	VoteIdentity VoteIdentity
 }
 
-func NewPolicyHandler(db *database.Database, auditLogger *utils.AuditLogger, wsHub *services.WebSocketHub, cache *services.Cache) *PolicyHandler {
+func NewPolicyHandler(db *database.Database, auditLogger *utils.AuditLogger, wsHub *services.WebSocketHub, events *services.EventBus, cache services.Cache, voteIdentity VoteIdentity) *PolicyHandler {
 	return &PolicyHandler{
-		DB:          db,
-		AuditLogger: auditLogger,
-		WSHub:       wsHub,
-		Cache:       cache,
+		DB:           db,
+		AuditLogger:  auditLogger,
+		WSHub:        wsHub,
+		Events:       events,
+		Cache:        cache,
+		VoteIdentity: voteIdentity,
 	}
 }
 
+// policySorts are the orderings GetPolicies supports, over the columns of
+// its per-policy subquery. Each ends with the policy ID so that cursors are
+// stable when the other keys tie. Most voted sorts on the live vote
+// counters; trending and controversial read the precomputed policy_scores
+// view.
+var policySorts = map[string]keyset{
+	"newest":        {Sort: "newest", Columns: []string{"p.created_at", "p.id"}, Desc: true},
+	"oldest":        {Sort: "oldest", Columns: []string{"p.created_at", "p.id"}},
+	"most_voted":    {Sort: "most_voted", Columns: []string{"p.upvotes + p.downvotes", "p.id"}, Desc: true},
+	"trending":      {Sort: "trending", Columns: []string{"p.hot_score", "p.id"}, Desc: true},
+	"controversial": {Sort: "controversial", Columns: []string{"p.controversy", "p.id"}, Desc: true},
+	"relevance":     {Sort: "relevance", Columns: []string{"p.rank", "p.created_at", "p.id"}, Desc: true},
+}
+
+// policyListTTL bounds how stale a cached policy list can get between
+// explicit invalidations, for changes that do not trigger one such as
+// votes, view counts and refreshed trending scores. Vote counts themselves
+// are always current, since GetPolicies reads them per request; only the
+// order of a vote-based sort can lag.
+const policyListTTL = 30 * time.Second
+
+// policyPage is the part of a GetPolicies response shared by every user,
+// which is what gets cached. Each user's own votes are added per request.
+type policyPage struct {
+	Policies   []map[string]interface{} `json:"policies"`
+	NextCursor *string                  `json:"next_cursor"`
+	Total      *int                     `json:"total"`
+}
+
+// GET /api/v1/policies?search=&status=&category=&sort=&lang=&limit=&cursor=&count=
 func (h *PolicyHandler) GetPolicies(c *fiber.Ctx) error {
-	search := c.Query("search", "")
+	search := strings.TrimSpace(c.Query("search", ""))
 	status := c.Query("status", "")
 	categoryID := c.Query("category", "")
-	sortBy := c.Query("sort", "newest")
+	sortBy := c.Query("sort", "")
+	lang := ""
+	if search != "" {
+		lang = c.Query("lang", "en")
+	}
+
+	if sortBy == "" && search != "" {
+		sortBy = "relevance"
+	}
+	if _, ok := policySorts[sortBy]; !ok || (sortBy == "relevance" && search == "") {
+		sortBy = "newest"
+	}
+	sort := policySorts[sortBy]
+
+	page, err := parsePageRequest(c, sort)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid cursor",
+		})
+	}
+
+	key := cacheKey(services.CachePrefixPolicies, sortBy, search, lang, status, categoryID,
+		strconv.Itoa(page.Limit), strconv.FormatBool(page.Count), c.Query("cursor", ""))
+
+	var result *policyPage
+	body, generation, ok := cachedJSON(h.Cache, key)
+	if ok {
+		result = &policyPage{}
+		if json.Unmarshal(body, result) != nil {
+			result = nil
+		}
+	}
+
+	if result == nil {
+		result, err = h.loadPolicyPage(search, lang, status, categoryID, sort, page)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to fetch policies",
+			})
+		}
+		cacheJSON(h.Cache, key, generation, result, policyListTTL)
+	}
 
+	userID := c.Locals("user_id").(string)
 	deviceFingerprint := c.Get("X-Device-Fingerprint", "")
 
-	query := `
+	ids := make([]string, len(result.Policies))
+	for i, p := range result.Policies {
+		ids[i], _ = p["id"].(string)
+	}
+
+	votes, participated, err := h.ownVotes(ids, userID, deviceFingerprint)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch policies",
+		})
+	}
+
+	counts, err := h.liveCounts(ids)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch policies",
+		})
+	}
+
+	for i, p := range result.Policies {
+		if count, ok := counts[ids[i]]; ok {
+			p["upvotes"] = count[0]
+			p["downvotes"] = count[1]
+		}
+		var currentUserVote *string
+		if vote, ok := votes[ids[i]]; ok {
+			currentUserVote = &vote
+		}
+		p["current_user_vote"] = currentUserVote
+		p["has_voted"] = currentUserVote != nil || participated[ids[i]]
+	}
+
+	return c.JSON(result)
+}
+
+// loadPolicyPage runs the public policy list query for one page.
+func (h *PolicyHandler) loadPolicyPage(search, lang, status, categoryID string, sort keyset, page pageRequest) (*policyPage, error) {
+	args := []interface{}{}
+	argIndex := 1
+
+	match := fullTextMatch{Rank: "0::real", Title: "NULL::text", Snippet: "NULL::text", CommentSnippet: "NULL::text"}
+	if search != "" {
+		match = newFullTextMatch(lang, argIndex)
+		args = append(args, search)
+		argIndex++
+	}
+
+	// The inner query computes one row per policy; highlights are only
+	// built for the page the outer query returns.
+	inner := `
 		SELECT 
 			p.id, p.title, p.description, p.status, p.admin_comment,
 			p.submitted_by, p.created_at, p.category_id, p.view_count,
+			p.voting_opens_at, p.voting_closes_at, p.secret_ballot,
+			` + match.Rank + ` as rank,
+			COALESCE(ps.hot_score, 0) as hot_score,
+			COALESCE(ps.controversy, 0) as controversy,
 			c.name_en as category_name,
-			COALESCE(SUM(CASE WHEN v.vote_type = 'upvote' THEN 1 ELSE 0 END), 0) as upvotes,
-			COALESCE(SUM(CASE WHEN v.vote_type = 'downvote' THEN 1 ELSE 0 END), 0) as downvotes,
-			EXISTS(
-				SELECT 1 FROM votes 
-				WHERE policy_id = p.id AND device_fingerprint = $1
-			) as current_user_vote
+			p.upvotes, p.downvotes
 		FROM policies p
-		LEFT JOIN votes v ON p.id = v.policy_id
 		LEFT JOIN categories c ON p.category_id = c.id
-		WHERE p.status IN ('approved', 'uncertain', 'rejected', 'in_progress', 'completed', 'on_hold', 'cannot_implement')
+		LEFT JOIN policy_scores ps ON p.id = ps.policy_id
+		WHERE p.status IN ('approved', 'uncertain', 'rejected', 'closed', 'in_progress', 'completed', 'on_hold', 'cannot_implement')
 	`
 
-	args := []interface{}{deviceFingerprint}
-	argIndex := 2
-
 	if search != "" {
-		query += fmt.Sprintf(` AND (p.title ILIKE $%d OR p.description ILIKE $%d)`, argIndex, argIndex)
-		args = append(args, "%"+search+"%")
-		argIndex++
+		inner += ` AND ` + match.Where
 	}
 
 	if status != "" {
-		query += fmt.Sprintf(` AND p.status = $%d`, argIndex)
+		inner += fmt.Sprintf(` AND p.status = $%d`, argIndex)
 		args = append(args, status)
 		argIndex++
 	}
 
 	if categoryID != "" {
-		query += fmt.Sprintf(` AND p.category_id = $%d`, argIndex)
+		inner += fmt.Sprintf(` AND p.category_id = $%d`, argIndex)
 		args = append(args, categoryID)
 		argIndex++
 	}
 
-	query += ` GROUP BY p.id, c.name_en`
+	result := &policyPage{Policies: []map[string]interface{}{}}
+	if page.Count {
+		total, err := countRows(h.DB.DB, inner, args)
+		if err != nil {
+			return nil, err
+		}
+		result.Total = total
+	}
+
+	query := `
+		SELECT
+			p.id, p.title, p.description, p.status, p.admin_comment,
+			p.submitted_by, p.created_at, p.category_id, p.view_count,
+			p.voting_opens_at, p.voting_closes_at, p.secret_ballot,
+			p.rank, p.hot_score, p.controversy,
+			` + match.Title + ` as title_highlight,
+			` + match.Snippet + ` as snippet,
+			` + match.CommentSnippet + ` as comment_snippet,
+			p.category_name, p.upvotes, p.downvotes
+		FROM (` + inner + `) p
+	`
 
-	switch sortBy {
-	case "oldest":
-		query += ` ORDER BY p.created_at ASC`
-	case "most_voted":
-		query += ` ORDER BY (COALESCE(SUM(CASE WHEN v.vote_type = 'upvote' THEN 1 ELSE 0 END), 0) + COALESCE(SUM(CASE WHEN v.vote_type = 'downvote' THEN 1 ELSE 0 END), 0)) DESC`
-	case "trending":
-		query += ` ORDER BY (COALESCE(SUM(CASE WHEN v.vote_type = 'upvote' THEN 1 ELSE 0 END), 0) + COALESCE(SUM(CASE WHEN v.vote_type = 'downvote' THEN 1 ELSE 0 END), 0)) DESC, p.created_at DESC`
-	default:
-		query += ` ORDER BY p.created_at DESC`
+	if page.After != nil {
+		query += ` WHERE ` + sort.After(argIndex)
+		args = append(args, page.After...)
+		argIndex += len(page.After)
 	}
 
+	query += sort.OrderBy() + fmt.Sprintf(` LIMIT $%d`, argIndex)
+	args = append(args, page.Limit+1)
+
 	rows, err := h.DB.DB.Query(query, args...)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Error: "Failed to fetch policies",
-		})
+		return nil, err
 	}
 	defer rows.Close()
 
-	policies := []map[string]interface{}{}
+	var lastKeys []string
 	for rows.Next() {
 		var p models.PolicyExtended
 		var categoryName sql.NullString
-		var currentUserVote bool
+		var rank, hotScore, controversy float64
+		var titleHighlight, snippet, commentSnippet *string
 
 		err := rows.Scan(
 			&p.ID, &p.Title, &p.Description, &p.Status, &p.AdminComment,
 			&p.SubmittedBy, &p.CreatedAt, &p.CategoryID, &p.ViewCount,
-			&categoryName, &p.Upvotes, &p.Downvotes, &currentUserVote,
+			&p.VotingOpensAt, &p.VotingClosesAt, &p.SecretBallot,
+			&rank, &hotScore, &controversy, &titleHighlight, &snippet, &commentSnippet,
+			&categoryName, &p.Upvotes, &p.Downvotes,
 		)
 		if err != nil {
 			continue
 		}
 
+		if len(result.Policies) == page.Limit {
+			cursor := utils.EncodeKeysetCursor(sort.Sort, lastKeys...)
+			result.NextCursor = &cursor
+			break
+		}
+
 		if categoryName.Valid {
 			p.CategoryName = &categoryName.String
 		}
 
 		policyMap := map[string]interface{}{
-			"id":                p.ID,
-			"title":             p.Title,
-			"description":       p.Description,
-			"status":            p.Status,
-			"admin_comment":     p.AdminComment,
-			"upvotes":           p.Upvotes,
-			"downvotes":         p.Downvotes,
-			"view_count":        p.ViewCount,
-			"created_at":        p.CreatedAt,
-			"current_user_vote": currentUserVote,
+			"id":               p.ID,
+			"title":            p.Title,
+			"description":      p.Description,
+			"status":           p.Status,
+			"admin_comment":    p.AdminComment,
+			"upvotes":          p.Upvotes,
+			"downvotes":        p.Downvotes,
+			"view_count":       p.ViewCount,
+			"created_at":       p.CreatedAt,
+			"secret_ballot":    p.SecretBallot,
+			"voting_opens_at":  p.VotingOpensAt,
+			"voting_closes_at": p.VotingClosesAt,
 		}
 
 		if p.CategoryName != nil {
@@ -132,45 +283,150 @@ func (h *PolicyHandler) GetPolicies(c *fiber.Ctx) error {
 		if p.CategoryID != nil {
 			policyMap["category_id"] = *p.CategoryID
 		}
+		if search != "" {
+			policyMap["rank"] = rank
+			policyMap["title_highlight"] = titleHighlight
+			policyMap["snippet"] = snippet
+			policyMap["comment_snippet"] = commentSnippet
+		}
 
-		policies = append(policies, policyMap)
+		result.Policies = append(result.Policies, policyMap)
+		lastKeys = policySortKeys(sort.Sort, &p, rank, hotScore, controversy)
 	}
 
-	return c.JSON(policies)
+	return result, nil
+}
+
+// liveCounts reads the current vote counters of the given policies, which
+// a cached page may show out of date.
+func (h *PolicyHandler) liveCounts(policyIDs []string) (map[string][2]int, error) {
+	counts := map[string][2]int{}
+	if len(policyIDs) == 0 {
+		return counts, nil
+	}
+
+	rows, err := h.DB.DB.Query(`
+		SELECT id, upvotes, downvotes FROM policies WHERE id = ANY($1)
+	`, pq.Array(policyIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var policyID string
+		var upvotes, downvotes int
+		if err := rows.Scan(&policyID, &upvotes, &downvotes); err != nil {
+			continue
+		}
+		counts[policyID] = [2]int{upvotes, downvotes}
+	}
+
+	return counts, rows.Err()
+}
+
+// ownVotes returns the caller's vote on each of the given policies, and
+// which of them the caller took part in by secret ballot.
+func (h *PolicyHandler) ownVotes(policyIDs []string, userID, deviceFingerprint string) (map[string]string, map[string]bool, error) {
+	votes := map[string]string{}
+	participated := map[string]bool{}
+	if len(policyIDs) == 0 {
+		return votes, participated, nil
+	}
+
+	ownVote, args := h.VoteIdentity.OwnVote("uv", userID, deviceFingerprint, []interface{}{pq.Array(policyIDs)})
+	rows, err := h.DB.DB.Query(`
+		SELECT uv.policy_id, uv.vote_type FROM votes uv
+		WHERE uv.policy_id = ANY($1) AND `+ownVote, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	for rows.Next() {
+		var policyID, voteType string
+		if err := rows.Scan(&policyID, &voteType); err != nil {
+			continue
+		}
+		votes[policyID] = voteType
+	}
+	rows.Close()
+
+	ownParticipation, args := h.VoteIdentity.OwnVote("vp", userID, deviceFingerprint, []interface{}{pq.Array(policyIDs)})
+	rows, err = h.DB.DB.Query(`
+		SELECT vp.policy_id FROM vote_participation vp
+		WHERE vp.policy_id = ANY($1) AND `+ownParticipation, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	for rows.Next() {
+		var policyID string
+		if err := rows.Scan(&policyID); err != nil {
+			continue
+		}
+		participated[policyID] = true
+	}
+	rows.Close()
+
+	return votes, participated, nil
+}
+
+// policySortKeys returns a policy's values for the columns of a policySorts
+// entry, for building the next page's cursor.
+func policySortKeys(sort string, p *models.PolicyExtended, rank, hotScore, controversy float64) []string {
+	switch sort {
+	case "most_voted":
+		return []string{strconv.Itoa(p.Upvotes + p.Downvotes), p.ID}
+	case "trending":
+		return []string{numberKey(hotScore), p.ID}
+	case "controversial":
+		return []string{numberKey(controversy), p.ID}
+	case "relevance":
+		return []string{numberKey(rank), timeKey(p.CreatedAt), p.ID}
+	}
+	return []string{timeKey(p.CreatedAt), p.ID}
 }
 
 func (h *PolicyHandler) GetPolicy(c *fiber.Ctx) error {
 	policyID := c.Params("id")
+	userID := c.Locals("user_id").(string)
 	deviceFingerprint := c.Get("X-Device-Fingerprint", "")
 
 	h.DB.DB.Exec(`UPDATE policies SET view_count = view_count + 1 WHERE id = $1`, policyID)
 
+	ownVote, args := h.VoteIdentity.OwnVote("uv", userID, deviceFingerprint, []interface{}{policyID})
+	ownParticipation, args := h.VoteIdentity.OwnVote("vp", userID, deviceFingerprint, args)
+
 	query := `
 		SELECT 
 			p.id, p.title, p.description, p.status, p.admin_comment, p.submitted_by,
 			p.created_at, p.category_id, p.view_count,
-			COALESCE(SUM(CASE WHEN v.vote_type = 'upvote' THEN 1 ELSE 0 END), 0) as upvotes,
-			COALESCE(SUM(CASE WHEN v.vote_type = 'downvote' THEN 1 ELSE 0 END), 0) as downvotes,
-			EXISTS(
-				SELECT 1 FROM votes 
-				WHERE policy_id = p.id AND device_fingerprint = $2
+			p.voting_opens_at, p.voting_closes_at, p.secret_ballot, p.merged_into, p.split_from,
+			p.upvotes, p.downvotes,
+			(
+				SELECT uv.vote_type FROM votes uv
+				WHERE uv.policy_id = p.id AND ` + ownVote + `
+				LIMIT 1
 			) as current_user_vote,
+			EXISTS(
+				SELECT 1 FROM vote_participation vp
+				WHERE vp.policy_id = p.id AND ` + ownParticipation + `
+			) as participated,
 			c.name_en as category_name
 		FROM policies p
-		LEFT JOIN votes v ON p.id = v.policy_id
 		LEFT JOIN categories c ON p.category_id = c.id
 		WHERE p.id = $1
-		GROUP BY p.id, c.name_en
 	`
 
 	var p models.PolicyExtended
-	var currentUserVote bool
+	var currentUserVote *string
+	var participated bool
 	var categoryName sql.NullString
+	var mergedInto, splitFrom *string
 
-	err := h.DB.DB.QueryRow(query, policyID, deviceFingerprint).Scan(
+	err := h.DB.DB.QueryRow(query, args...).Scan(
 		&p.ID, &p.Title, &p.Description, &p.Status, &p.AdminComment, &p.SubmittedBy,
 		&p.CreatedAt, &p.CategoryID, &p.ViewCount,
-		&p.Upvotes, &p.Downvotes, &currentUserVote, &categoryName,
+		&p.VotingOpensAt, &p.VotingClosesAt, &p.SecretBallot, &mergedInto, &splitFrom,
+		&p.Upvotes, &p.Downvotes, &currentUserVote, &participated, &categoryName,
 	)
 
 	if err == sql.ErrNoRows {
@@ -194,8 +450,14 @@ func (h *PolicyHandler) GetPolicy(c *fiber.Ctx) error {
 		"view_count":        p.ViewCount,
 		"created_at":        p.CreatedAt,
 		"current_user_vote": currentUserVote,
+		"has_voted":         currentUserVote != nil || participated,
+		"secret_ballot":     p.SecretBallot,
 		"category_name":     p.CategoryName,
 		"category_id":       p.CategoryID,
+		"voting_opens_at":   p.VotingOpensAt,
+		"voting_closes_at":  p.VotingClosesAt,
+		"merged_into":       mergedInto,
+		"split_from":        splitFrom,
 	}
 
 	return c.JSON(response)
@@ -204,10 +466,16 @@ func (h *PolicyHandler) GetPolicy(c *fiber.Ctx) error {
 func (h *PolicyHandler) CreatePolicy(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 
+	// Submissions that look like an existing policy, or like one the
+	// student already has waiting for review, are turned away with the
+	// likely duplicates unless ignore_duplicates is set, so students can
+	// vote on the existing one instead. Other students' pending policies
+	// are not checked since their content is not public yet.
 	var req struct {
-		Title       string  `json:"title"`
-		Description string  `json:"description"`
-		CategoryID  *string `json:"category_id"`
+		Title            string  `json:"title"`
+		Description      string  `json:"description"`
+		CategoryID       *string `json:"category_id"`
+		IgnoreDuplicates bool    `json:"ignore_duplicates"`
 	}
 
 	if err := c.BodyParser(&req); err != nil {
@@ -234,8 +502,38 @@ func (h *PolicyHandler) CreatePolicy(c *fiber.Ctx) error {
 		})
 	}
 
+	duplicates, err := services.FindSimilarPolicies(h.DB.DB, req.Title, req.Description, services.PublicStatuses, nil, nil, 5)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to check for similar policies",
+		})
+	}
+
+	ownPending, err := services.FindSimilarPolicies(h.DB.DB, req.Title, req.Description, []string{"pending"}, nil, &userID, 5)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to check for similar policies",
+		})
+	}
+	duplicates = append(duplicates, ownPending...)
+
+	if len(duplicates) > 0 && !req.IgnoreDuplicates {
+		return c.Status(fiber.StatusConflict).JSON(map[string]interface{}{
+			"error":      "Similar policies already exist",
+			"duplicates": duplicates,
+		})
+	}
+
+	tx, err := h.DB.DB.Begin()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+	defer tx.Rollback()
+
 	var policyID string
-	err := h.DB.DB.QueryRow(`
+	err = tx.QueryRow(`
 		INSERT INTO policies (title, description, submitted_by, status, category_id)
 		VALUES ($1, $2, $3, 'pending', $4)
 		RETURNING id
@@ -247,15 +545,394 @@ func (h *PolicyHandler) CreatePolicy(c *fiber.Ctx) error {
 		})
 	}
 
+	if _, err := services.RecordRevision(tx, policyID, userID, "create", nil); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to create policy",
+		})
+	}
+
+	if err := services.RecordStatusChange(tx, policyID, nil, "pending", &userID, nil); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to create policy",
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to create policy",
+		})
+	}
+
 	if h.AuditLogger != nil {
-		h.AuditLogger.Log(userID, "create_policy", "policy", policyID, map[string]interface{}{
+		details := map[string]interface{}{
 			"title": req.Title,
-		})
+		}
+		if len(duplicates) > 0 {
+			ids := []string{}
+			for _, d := range duplicates {
+				ids = append(ids, d.ID)
+			}
+			details["ignored_duplicates"] = ids
+		}
+		h.AuditLogger.Log(userID, "create_policy", "policy", policyID, details)
 	}
 
+	services.InvalidatePolicies(h.Cache)
+	h.Events.Publish(services.DomainEvent{Type: services.EventPolicyCreated, PolicyID: policyID})
+
 	return c.Status(fiber.StatusCreated).JSON(models.MessageResponse{
 		ID:      policyID,
 		Status:  "pending",
 		Message: "Submitted for review",
 	})
 }
+
+// GET /api/v1/policies/:id/timeline
+func (h *PolicyHandler) GetPolicyTimeline(c *fiber.Ctx) error {
+	policyID := c.Params("id")
+	role := c.Locals("role").(string)
+
+	rows, err := h.DB.DB.Query(`
+		SELECT sh.id, sh.policy_id, sh.from_status, sh.to_status, sh.comment,
+			sh.changed_by, u.role, sh.created_at
+		FROM policy_status_history sh
+		LEFT JOIN users u ON sh.changed_by = u.id
+		WHERE sh.policy_id = $1
+		ORDER BY sh.created_at ASC
+	`, policyID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch timeline",
+		})
+	}
+	defer rows.Close()
+
+	timeline := []models.PolicyStatusChange{}
+	for rows.Next() {
+		var change models.PolicyStatusChange
+		err := rows.Scan(
+			&change.ID, &change.PolicyID, &change.FromStatus, &change.ToStatus, &change.Comment,
+			&change.ChangedBy, &change.ActorRole, &change.CreatedAt,
+		)
+		if err != nil {
+			continue
+		}
+
+		if !isModerator(role) {
+			change.ChangedBy = nil
+		}
+		timeline = append(timeline, change)
+	}
+
+	return c.JSON(timeline)
+}
+
+// GET /api/v1/policies/similar?title=&description=
+//
+// Lets the submit form suggest existing policies while a student types.
+func (h *PolicyHandler) GetSimilarPolicies(c *fiber.Ctx) error {
+	title := strings.TrimSpace(c.Query("title", ""))
+	description := strings.TrimSpace(c.Query("description", ""))
+
+	if len(title) < 3 && len(description) < 3 {
+		return c.JSON([]models.SimilarPolicy{})
+	}
+
+	similar, err := services.FindSimilarPolicies(h.DB.DB, title, description, services.PublicStatuses, nil, nil, 5)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to check for similar policies",
+		})
+	}
+
+	return c.JSON(similar)
+}
+
+// GET /api/v1/policies/mine
+func (h *PolicyHandler) GetMyPolicies(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	rows, err := h.DB.DB.Query(`
+		SELECT
+			p.id, p.title, p.description, p.status, p.admin_comment,
+			p.created_at, p.category_id, c.name_en,
+			p.upvotes, p.downvotes,
+			(
+				SELECT MAX(sh.created_at) FROM policy_status_history sh
+				WHERE sh.policy_id = p.id
+			) as status_changed_at,
+			COALESCE((
+				SELECT f.kind = 'changes_requested' FROM policy_feedback f
+				WHERE f.policy_id = p.id AND f.kind IN ('changes_requested', 'resubmitted')
+				ORDER BY f.created_at DESC
+				LIMIT 1
+			), false) as changes_requested,
+			(SELECT COUNT(*) FROM policy_feedback f WHERE f.policy_id = p.id) as feedback_count
+		FROM policies p
+		LEFT JOIN categories c ON p.category_id = c.id
+		WHERE p.submitted_by = $1
+		ORDER BY p.created_at DESC
+	`, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch policies",
+		})
+	}
+	defer rows.Close()
+
+	policies := []map[string]interface{}{}
+	for rows.Next() {
+		var p models.PolicyExtended
+		var categoryName sql.NullString
+		var statusChangedAt sql.NullTime
+		var changesRequested bool
+		var feedbackCount int
+
+		err := rows.Scan(
+			&p.ID, &p.Title, &p.Description, &p.Status, &p.AdminComment,
+			&p.CreatedAt, &p.CategoryID, &categoryName,
+			&p.Upvotes, &p.Downvotes, &statusChangedAt, &changesRequested, &feedbackCount,
+		)
+		if err != nil {
+			continue
+		}
+
+		policyMap := map[string]interface{}{
+			"id":                p.ID,
+			"title":             p.Title,
+			"description":       p.Description,
+			"status":            p.Status,
+			"admin_comment":     p.AdminComment,
+			"category_id":       p.CategoryID,
+			"upvotes":           p.Upvotes,
+			"downvotes":         p.Downvotes,
+			"created_at":        p.CreatedAt,
+			"can_edit":          p.Status == "pending",
+			"changes_requested": p.Status == "pending" && changesRequested,
+			"feedback_count":    feedbackCount,
+		}
+
+		if categoryName.Valid {
+			policyMap["category_name"] = categoryName.String
+		}
+		if statusChangedAt.Valid {
+			policyMap["status_changed_at"] = statusChangedAt.Time
+		}
+
+		policies = append(policies, policyMap)
+	}
+
+	return c.JSON(policies)
+}
+
+// PUT /api/v1/policies/:id
+//
+// Authors may revise their own submission until a reviewer has acted on it.
+// Each revision is posted to the review thread as a resubmission, with the
+// optional note explaining what changed.
+func (h *PolicyHandler) UpdateOwnPolicy(c *fiber.Ctx) error {
+	policyID := c.Params("id")
+	userID := c.Locals("user_id").(string)
+
+	var req struct {
+		Title       string  `json:"title"`
+		Description string  `json:"description"`
+		CategoryID  *string `json:"category_id"`
+		Note        string  `json:"note"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid request body",
+		})
+	}
+
+	if len(req.Title) < 10 || len(req.Title) > 200 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Title must be between 10 and 200 characters",
+		})
+	}
+
+	if len(req.Description) < 50 || len(req.Description) > 2000 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Description must be between 50 and 2000 characters",
+		})
+	}
+
+	if len(req.Note) > 2000 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Note must be at most 2000 characters",
+		})
+	}
+
+	if utils.ContainsProfanity(req.Title) || utils.ContainsProfanity(req.Description) || utils.ContainsProfanity(req.Note) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Content contains inappropriate language",
+		})
+	}
+
+	tx, err := h.DB.DB.Begin()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+	defer tx.Rollback()
+
+	code, msg := lockOwnPendingPolicy(tx, policyID, userID, "edited")
+	if code != 0 {
+		return c.Status(code).JSON(models.ErrorResponse{Error: msg})
+	}
+
+	var previousTitle, previousDescription string
+	var previousCategoryID *string
+	err = tx.QueryRow(`
+		SELECT title, description, category_id FROM policies WHERE id = $1
+	`, policyID).Scan(&previousTitle, &previousDescription, &previousCategoryID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to update policy",
+		})
+	}
+
+	// Saving the form unchanged only records the note, if there is one, so
+	// the history does not fill with identical revisions.
+	note := strings.TrimSpace(req.Note)
+	changed := req.Title != previousTitle || req.Description != previousDescription ||
+		!sameID(req.CategoryID, previousCategoryID)
+	if !changed && note == "" {
+		return c.JSON(models.MessageResponse{
+			ID:      policyID,
+			Status:  "pending",
+			Message: "No changes to save",
+		})
+	}
+
+	var revision int
+	if changed {
+		_, err = tx.Exec(`
+			UPDATE policies SET title = $1, description = $2, category_id = $3 WHERE id = $4
+		`, req.Title, req.Description, req.CategoryID, policyID)
+		if isForeignKeyViolation(err) {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error: "Category not found",
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to update policy",
+			})
+		}
+
+		revision, err = services.RecordRevision(tx, policyID, userID, "edit", nil)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to update policy",
+			})
+		}
+	}
+
+	if _, err := addFeedback(tx, policyID, userID, "resubmitted", note); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to update policy",
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to update policy",
+		})
+	}
+
+	if h.AuditLogger != nil && changed {
+		h.AuditLogger.Log(userID, "edit_own_policy", "policy", policyID, map[string]interface{}{
+			"title":    req.Title,
+			"revision": revision,
+		})
+	}
+
+	if !sameID(req.CategoryID, previousCategoryID) {
+		h.Events.Publish(services.DomainEvent{
+			Type:               services.EventCategoryChanged,
+			PolicyID:           policyID,
+			PreviousCategoryID: derefID(previousCategoryID),
+		})
+	}
+
+	return c.JSON(models.MessageResponse{
+		ID:      policyID,
+		Status:  "pending",
+		Message: "Submission updated",
+	})
+}
+
+// POST /api/v1/policies/:id/withdraw
+func (h *PolicyHandler) WithdrawPolicy(c *fiber.Ctx) error {
+	policyID := c.Params("id")
+	userID := c.Locals("user_id").(string)
+
+	tx, err := h.DB.DB.Begin()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+	defer tx.Rollback()
+
+	code, msg := lockOwnPendingPolicy(tx, policyID, userID, "withdrawn")
+	if code != 0 {
+		return c.Status(code).JSON(models.ErrorResponse{Error: msg})
+	}
+
+	if _, err := services.WithdrawPolicy(tx, policyID, userID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to withdraw policy",
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to withdraw policy",
+		})
+	}
+
+	if h.AuditLogger != nil {
+		h.AuditLogger.Log(userID, "withdraw_policy", "policy", policyID, nil)
+	}
+
+	services.InvalidatePolicies(h.Cache)
+	h.Events.PublishStatusChange(policyID, "pending", "withdrawn")
+
+	return c.JSON(models.MessageResponse{
+		ID:      policyID,
+		Status:  "withdrawn",
+		Message: "Submission withdrawn",
+	})
+}
+
+// lockOwnPendingPolicy locks a policy for its author and checks it is still
+// awaiting review. It returns a non-zero status code and message when the
+// author may not change it; verb completes "can no longer be ...".
+func lockOwnPendingPolicy(tx *sql.Tx, policyID, userID, verb string) (int, string) {
+	var submittedBy *string
+	var status string
+	err := tx.QueryRow(`
+		SELECT submitted_by, status FROM policies WHERE id = $1 FOR UPDATE
+	`, policyID).Scan(&submittedBy, &status)
+	if err == sql.ErrNoRows {
+		return fiber.StatusNotFound, "Policy not found"
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, "Database error"
+	}
+
+	if submittedBy == nil || *submittedBy != userID {
+		return fiber.StatusForbidden, "Only the author can change this policy"
+	}
+
+	if status != "pending" {
+		return fiber.StatusConflict, "This policy has been reviewed and can no longer be " + verb
+	}
+
+	return 0, ""
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"database/sql"
+	"strings"
+	"vote/internal/database"
+	"vote/internal/models"
+	"vote/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+)
+
+type ReportHandler struct {
+	DB          *database.Database
+	AuditLogger *utils.AuditLogger
+	Threshold   int
+}
+
+func NewReportHandler(db *database.Database, auditLogger *utils.AuditLogger, threshold int) *ReportHandler {
+	return &ReportHandler{
+		DB:          db,
+		AuditLogger: auditLogger,
+		Threshold:   threshold,
+	}
+}
+
+// POST /api/v1/comments/:id/report
+func (h *ReportHandler) ReportComment(c *fiber.Ctx) error {
+	commentID := c.Params("id")
+	userID := c.Locals("user_id").(string)
+
+	var req models.ReportCommentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid request body",
+		})
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if len(req.Reason) < 1 || len(req.Reason) > 500 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Reason must be between 1 and 500 characters",
+		})
+	}
+
+	tx, err := h.DB.DB.Begin()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+	defer tx.Rollback()
+
+	var ownerID, status string
+	err = tx.QueryRow(`
+		SELECT user_id, moderation_status FROM comments WHERE id = $1 FOR UPDATE
+	`, commentID).Scan(&ownerID, &status)
+	if err == sql.ErrNoRows || (err == nil && status == "removed") {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "Comment not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+
+	if ownerID == userID {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "You cannot report your own comment",
+		})
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO comment_reports (comment_id, reporter_id, reason)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (comment_id, reporter_id) DO NOTHING
+	`, commentID, userID, req.Reason)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to report comment",
+		})
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Error: "You have already reported this comment",
+		})
+	}
+
+	var pendingReports int
+	tx.QueryRow(`
+		SELECT COUNT(*) FROM comment_reports WHERE comment_id = $1 AND status = 'pending'
+	`, commentID).Scan(&pendingReports)
+
+	held := false
+	if status == "visible" && pendingReports >= h.Threshold {
+		if _, err := tx.Exec(`UPDATE comments SET moderation_status = 'held' WHERE id = $1`, commentID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to report comment",
+			})
+		}
+		held = true
+	}
+
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to report comment",
+		})
+	}
+
+	if h.AuditLogger != nil {
+		h.AuditLogger.Log(userID, "report_comment", "comment", commentID, map[string]interface{}{
+			"reason": req.Reason,
+		})
+		if held {
+			h.AuditLogger.Log(userID, "auto_hold_comment", "comment", commentID, map[string]interface{}{
+				"pending_reports": pendingReports,
+				"threshold":       h.Threshold,
+			})
+		}
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.MessageResponse{
+		Message: "Comment reported",
+	})
+}
+
+// GET /api/v1/admin/reports?status=pending
+func (h *ReportHandler) GetReports(c *fiber.Ctx) error {
+	status := c.Query("status", "pending")
+
+	if status != "pending" && status != "resolved" && status != "dismissed" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid status",
+		})
+	}
+
+	rows, err := h.DB.DB.Query(`
+		SELECT
+			c.id, c.policy_id, c.comment_text, c.moderation_status,
+			COUNT(r.id) as report_count,
+			ARRAY_AGG(r.reason ORDER BY r.created_at) as reasons,
+			MIN(r.created_at) as first_reported_at,
+			MAX(r.created_at) as last_reported_at
+		FROM comment_reports r
+		JOIN comments c ON r.comment_id = c.id
+		WHERE r.status = $1
+		GROUP BY c.id
+		ORDER BY report_count DESC, last_reported_at DESC
+	`, status)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch reports",
+		})
+	}
+	defer rows.Close()
+
+	reports := []models.CommentReportSummary{}
+	for rows.Next() {
+		var r models.CommentReportSummary
+		err := rows.Scan(
+			&r.CommentID, &r.PolicyID, &r.CommentText, &r.ModerationStatus,
+			&r.ReportCount, pq.Array(&r.Reasons), &r.FirstReportedAt, &r.LastReportedAt,
+		)
+		if err != nil {
+			continue
+		}
+		reports = append(reports, r)
+	}
+
+	return c.JSON(reports)
+}
+
+// POST /api/v1/admin/reports/:commentId/resolve
+//
+// Upholds the pending reports and applies the chosen moderation action
+// (hide by default, or remove).
+func (h *ReportHandler) ResolveReports(c *fiber.Ctx) error {
+	var req models.ResolveReportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid request body",
+		})
+	}
+
+	moderationStatus := "hidden"
+	switch req.Action {
+	case "", "hide":
+	case "remove":
+		moderationStatus = "removed"
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Action must be hide or remove",
+		})
+	}
+
+	return h.closeReports(c, "resolved", moderationStatus, req.Note)
+}
+
+// POST /api/v1/admin/reports/:commentId/dismiss
+//
+// Rejects the pending reports and makes a held comment visible again.
+func (h *ReportHandler) DismissReports(c *fiber.Ctx) error {
+	var req models.ResolveReportRequest
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid request body",
+		})
+	}
+
+	return h.closeReports(c, "dismissed", "visible", req.Note)
+}
+
+func (h *ReportHandler) closeReports(c *fiber.Ctx, reportStatus, moderationStatus string, note *string) error {
+	commentID := c.Params("commentId")
+	userID := c.Locals("user_id").(string)
+
+	tx, err := h.DB.DB.Begin()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE comment_reports
+		SET status = $1, resolved_by = $2, resolved_at = NOW(), resolution_note = $3
+		WHERE comment_id = $4 AND status = 'pending'
+	`, reportStatus, userID, note, commentID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to update reports",
+		})
+	}
+
+	closed, _ := result.RowsAffected()
+	if closed == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "No pending reports for this comment",
+		})
+	}
+
+	// Dismissing only lifts an automatic hold; it never overrides a
+	// moderator's earlier decision to hide or remove the comment.
+	query := `UPDATE comments SET moderation_status = $1 WHERE id = $2`
+	if reportStatus == "dismissed" {
+		query += ` AND moderation_status = 'held'`
+	}
+	if _, err := tx.Exec(query, moderationStatus, commentID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to update comment",
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to update reports",
+		})
+	}
+
+	if h.AuditLogger != nil {
+		action := "resolve_reports"
+		if reportStatus == "dismissed" {
+			action = "dismiss_reports"
+		}
+		h.AuditLogger.Log(userID, action, "comment", commentID, map[string]interface{}{
+			"reports":           closed,
+			"moderation_status": moderationStatus,
+			"note":              note,
+		})
+	}
+
+	return c.JSON(models.MessageResponse{
+		Message: "Reports " + reportStatus,
+		Status:  reportStatus,
+	})
+}
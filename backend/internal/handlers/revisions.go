@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"database/sql"
+	"strconv"
+	"vote/internal/database"
+	"vote/internal/models"
+	"vote/internal/services"
+	"vote/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RevisionHandler struct {
+	DB          *database.Database
+	AuditLogger *utils.AuditLogger
+	Events      *services.EventBus
+	Cache       services.Cache
+}
+
+func NewRevisionHandler(db *database.Database, auditLogger *utils.AuditLogger, events *services.EventBus, cache services.Cache) *RevisionHandler {
+	return &RevisionHandler{
+		DB:          db,
+		AuditLogger: auditLogger,
+		Events:      events,
+		Cache:       cache,
+	}
+}
+
+// GET /api/v1/policies/:id/revisions
+func (h *RevisionHandler) GetRevisions(c *fiber.Ctx) error {
+	policyID := c.Params("id")
+	role := c.Locals("role").(string)
+
+	rows, err := h.DB.DB.Query(`
+		SELECT r.id, r.policy_id, r.revision, r.title, r.description, r.category_id,
+			r.action, r.restored_from, r.origin_policy_id, r.merged_from, r.edited_by, u.role, r.created_at
+		FROM policy_revisions r
+		LEFT JOIN users u ON r.edited_by = u.id
+		WHERE r.policy_id = $1
+		ORDER BY r.revision DESC
+	`, policyID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch revisions",
+		})
+	}
+	defer rows.Close()
+
+	revisions := []models.PolicyRevision{}
+	for rows.Next() {
+		var rev models.PolicyRevision
+		err := rows.Scan(
+			&rev.ID, &rev.PolicyID, &rev.Revision, &rev.Title, &rev.Description, &rev.CategoryID,
+			&rev.Action, &rev.RestoredFrom, &rev.OriginPolicyID, &rev.MergedFrom, &rev.EditedBy, &rev.EditorRole, &rev.CreatedAt,
+		)
+		if err != nil {
+			continue
+		}
+
+		// Students see that an admin or the author edited, not who.
+		if !isModerator(role) {
+			rev.EditedBy = nil
+		}
+		revisions = append(revisions, rev)
+	}
+
+	if len(revisions) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "Policy not found",
+		})
+	}
+
+	return c.JSON(revisions)
+}
+
+// GET /api/v1/policies/:id/revisions/diff?from=&to=
+//
+// Defaults to comparing the latest revision with the one before it.
+// Revisions merged in from another policy are not part of this policy's
+// text and are skipped.
+func (h *RevisionHandler) DiffRevisions(c *fiber.Ctx) error {
+	policyID := c.Params("id")
+
+	var latest int
+	h.DB.DB.QueryRow(`
+		SELECT COALESCE(MAX(revision), 0) FROM policy_revisions
+		WHERE policy_id = $1 AND merged_from IS NULL
+	`, policyID).Scan(&latest)
+	if latest == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "Policy not found",
+		})
+	}
+
+	to := c.QueryInt("to", latest)
+	from := c.QueryInt("from", 0)
+	if from == 0 {
+		h.DB.DB.QueryRow(`
+			SELECT COALESCE(MAX(revision), 0) FROM policy_revisions
+			WHERE policy_id = $1 AND merged_from IS NULL AND revision < $2
+		`, policyID, to).Scan(&from)
+	}
+	if from < 1 {
+		from = 1
+	}
+
+	fromRev, err := h.loadRevision(policyID, from)
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "Revision not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch revisions",
+		})
+	}
+
+	toRev, err := h.loadRevision(policyID, to)
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "Revision not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch revisions",
+		})
+	}
+
+	return c.JSON(map[string]interface{}{
+		"from":             fromRev.Revision,
+		"to":               toRev.Revision,
+		"title":            utils.DiffWords(fromRev.Title, toRev.Title),
+		"description":      utils.DiffWords(fromRev.Description, toRev.Description),
+		"from_category_id": fromRev.CategoryID,
+		"to_category_id":   toRev.CategoryID,
+		"category_changed": !sameID(fromRev.CategoryID, toRev.CategoryID),
+	})
+}
+
+// POST /api/v1/admin/policies/:id/revisions/:revision/restore
+//
+// Restoring copies an old revision's text back onto the policy and records
+// the result as a new revision, so nothing in the history is lost.
+func (h *RevisionHandler) RestoreRevision(c *fiber.Ctx) error {
+	policyID := c.Params("id")
+	userID := c.Locals("user_id").(string)
+
+	revision, err := strconv.Atoi(c.Params("revision"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid revision",
+		})
+	}
+
+	rev, err := h.loadRevision(policyID, revision)
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "Revision not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+
+	tx, err := h.DB.DB.Begin()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+	defer tx.Rollback()
+
+	if code, msg := lockRestorablePolicy(tx, policyID); code != 0 {
+		return c.Status(code).JSON(models.ErrorResponse{
+			Error: msg,
+		})
+	}
+
+	var previousCategoryID *string
+	err = tx.QueryRow(`
+		UPDATE policies p SET title = $1, description = $2, category_id = $3
+		FROM policies old
+		WHERE p.id = $4 AND old.id = p.id
+		RETURNING old.category_id
+	`, rev.Title, rev.Description, rev.CategoryID, policyID).Scan(&previousCategoryID)
+	if isForeignKeyViolation(err) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Category not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to restore revision",
+		})
+	}
+
+	newRevision, err := services.RecordRevision(tx, policyID, userID, "restore", &revision)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to restore revision",
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to restore revision",
+		})
+	}
+
+	if h.AuditLogger != nil {
+		h.AuditLogger.Log(userID, "restore_policy_revision", "policy", policyID, map[string]interface{}{
+			"restored_from": revision,
+			"revision":      newRevision,
+		})
+	}
+
+	services.InvalidatePolicies(h.Cache)
+
+	if !sameID(rev.CategoryID, previousCategoryID) {
+		h.Events.Publish(services.DomainEvent{
+			Type:               services.EventCategoryChanged,
+			PolicyID:           policyID,
+			PreviousCategoryID: derefID(previousCategoryID),
+		})
+	}
+
+	return c.JSON(map[string]interface{}{
+		"message":  "Revision restored",
+		"revision": newRevision,
+	})
+}
+
+// lockRestorablePolicy locks the policy for a restore and returns a non-zero
+// status code and message when its text must not change: once it has been
+// merged or withdrawn, or while people are voting on it.
+func lockRestorablePolicy(tx *sql.Tx, policyID string) (int, string) {
+	var status string
+	var votingOpen bool
+	err := tx.QueryRow(`
+		SELECT status,
+			status IN ('approved', 'uncertain', 'rejected')
+				AND (voting_opens_at IS NULL OR voting_opens_at <= NOW())
+				AND (voting_closes_at IS NULL OR voting_closes_at > NOW())
+		FROM policies WHERE id = $1 FOR UPDATE
+	`, policyID).Scan(&status, &votingOpen)
+	if err == sql.ErrNoRows {
+		return fiber.StatusNotFound, "Policy not found"
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, "Database error"
+	}
+
+	if status == "merged" || status == "withdrawn" {
+		return fiber.StatusConflict, "Cannot restore a revision of a " + status + " policy"
+	}
+	if votingOpen {
+		return fiber.StatusConflict, "Cannot restore a revision while voting is open"
+	}
+
+	return 0, ""
+}
+
+// loadRevision finds one of the policy's own revisions. Revisions merged in
+// from another policy are reported as missing so they cannot be restored or
+// diffed as this policy's text.
+func (h *RevisionHandler) loadRevision(policyID string, revision int) (*models.PolicyRevision, error) {
+	var rev models.PolicyRevision
+	err := h.DB.DB.QueryRow(`
+		SELECT id, policy_id, revision, title, description, category_id, action, restored_from, created_at
+		FROM policy_revisions
+		WHERE policy_id = $1 AND revision = $2 AND merged_from IS NULL
+	`, policyID, revision).Scan(
+		&rev.ID, &rev.PolicyID, &rev.Revision, &rev.Title, &rev.Description, &rev.CategoryID,
+		&rev.Action, &rev.RestoredFrom, &rev.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+func sameID(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// derefID returns the ID a nullable column holds, or "" for NULL.
+func derefID(id *string) string {
+	if id == nil {
+		return ""
+	}
+	return *id
+}
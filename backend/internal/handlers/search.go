@@ -0,0 +1,57 @@
+package handlers
+
+import "fmt"
+
+// searchConfigs maps the lang query parameter to a text search configuration
+// and the generated tsvector column built with it. Both configurations fold
+// diacritics, so searches match with or without them.
+var searchConfigs = map[string][2]string{
+	"en": {"vote_en", "search_en"},
+	"ro": {"vote_ro", "search_ro"},
+}
+
+// headlineOptions marks matches with <mark>. The text is HTML-escaped
+// before ts_headline runs so the markers are the only markup in the result.
+const headlineOptions = `'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" ... "'`
+
+// fullTextMatch holds the SQL fragments for a ranked full-text search over
+// policies (alias p) and their visible comments.
+type fullTextMatch struct {
+	Where          string
+	Rank           string
+	Title          string
+	Snippet        string
+	CommentSnippet string
+}
+
+// newFullTextMatch builds the search fragments for lang, reading the query
+// text from placeholder $argIndex. A policy matches on its own text or on
+// any visible comment; comment matches count for half as much in the rank.
+func newFullTextMatch(lang string, argIndex int) fullTextMatch {
+	cfg, ok := searchConfigs[lang]
+	if !ok {
+		cfg = searchConfigs["en"]
+	}
+	config, column := cfg[0], cfg[1]
+	query := fmt.Sprintf(`websearch_to_tsquery('%s', $%d)`, config, argIndex)
+
+	commentMatch := fmt.Sprintf(`
+		FROM comments cm
+		WHERE cm.policy_id = p.id AND cm.moderation_status = 'visible' AND cm.%s @@ %s`, column, query)
+
+	return fullTextMatch{
+		Where: fmt.Sprintf(`(p.%s @@ %s OR EXISTS (SELECT 1 %s))`, column, query, commentMatch),
+		Rank: fmt.Sprintf(`(ts_rank_cd(p.%s, %s) + 0.5 * COALESCE((SELECT MAX(ts_rank_cd(cm.%s, %s)) %s), 0))`,
+			column, query, column, query, commentMatch),
+		Title: fmt.Sprintf(`ts_headline('%s', %s, %s, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>')`,
+			config, escapeHTMLSQL("p.title"), query),
+		Snippet: fmt.Sprintf(`ts_headline('%s', %s, %s, %s)`,
+			config, escapeHTMLSQL("p.description"), query, headlineOptions),
+		CommentSnippet: fmt.Sprintf(`(SELECT ts_headline('%s', %s, %s, %s) %s ORDER BY ts_rank_cd(cm.%s, %s) DESC LIMIT 1)`,
+			config, escapeHTMLSQL("cm.comment_text"), query, headlineOptions, commentMatch, column, query),
+	}
+}
+
+func escapeHTMLSQL(column string) string {
+	return fmt.Sprintf(`replace(replace(replace(%s, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')`, column)
+}
@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"database/sql"
+	"fmt"
 	"vote/internal/database"
 	"vote/internal/models"
+	"vote/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -18,11 +20,36 @@ func NewSuperuserHandler(db *database.Database) *SuperuserHandler {
 
 // GET /api/v1/superuser/users
 func (h *SuperuserHandler) GetAllUsers(c *fiber.Ctx) error {
-	rows, err := h.DB.DB.Query(`
-		SELECT id, role, login_code, is_active, created_at
-		FROM users
-		ORDER BY created_at DESC
-	`)
+	ks := keyset{Sort: "newest", Columns: []string{"created_at", "id"}, Desc: true}
+	page, err := parsePageRequest(c, ks)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid cursor",
+		})
+	}
+
+	var total *int
+	if page.Count {
+		total, err = countRows(h.DB.DB, `SELECT id FROM users`, nil)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error: "Failed to fetch users",
+			})
+		}
+	}
+
+	query := `SELECT id, role, login_code, is_active, created_at FROM users`
+	args := []interface{}{}
+	argIndex := 1
+	if page.After != nil {
+		query += " WHERE " + ks.After(argIndex)
+		args = append(args, page.After...)
+		argIndex += len(page.After)
+	}
+	query += ks.OrderBy() + fmt.Sprintf(" LIMIT $%d", argIndex)
+	args = append(args, page.Limit+1)
+
+	rows, err := h.DB.DB.Query(query, args...)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
 			Error: "Failed to fetch users",
@@ -31,6 +58,7 @@ func (h *SuperuserHandler) GetAllUsers(c *fiber.Ctx) error {
 	defer rows.Close()
 
 	users := []models.User{}
+	var nextCursor *string
 	for rows.Next() {
 		var u models.User
 		var loginCode sql.NullString
@@ -38,13 +66,23 @@ func (h *SuperuserHandler) GetAllUsers(c *fiber.Ctx) error {
 		if err != nil {
 			continue
 		}
+		if len(users) == page.Limit {
+			last := users[len(users)-1]
+			cursor := utils.EncodeKeysetCursor(ks.Sort, timeKey(last.CreatedAt), last.ID)
+			nextCursor = &cursor
+			break
+		}
 		if loginCode.Valid {
 			u.LoginCode = &loginCode.String
 		}
 		users = append(users, u)
 	}
 
-	return c.JSON(users)
+	return c.JSON(map[string]interface{}{
+		"users":       users,
+		"next_cursor": nextCursor,
+		"total":       total,
+	})
 }
 
 // POST /api/v1/superuser/users
@@ -0,0 +1,58 @@
+package handlers
+
+import "fmt"
+
+// VoteIdentity decides what counts as "the same voter" when enforcing one
+// vote per policy.
+type VoteIdentity string
+
+const (
+	// VoteIdentityUser allows one vote per login code, from any device.
+	VoteIdentityUser VoteIdentity = "user"
+	// VoteIdentityDevice allows one vote per device, so a login code shared
+	// by a classroom can vote once from each student's device.
+	VoteIdentityDevice VoteIdentity = "device"
+	// VoteIdentityUserDevice requires both the login code and the device to
+	// be unused for the policy.
+	VoteIdentityUserDevice VoteIdentity = "user_device"
+)
+
+func ParseVoteIdentity(s string) VoteIdentity {
+	switch VoteIdentity(s) {
+	case VoteIdentityDevice, VoteIdentityUserDevice:
+		return VoteIdentity(s)
+	default:
+		return VoteIdentityUser
+	}
+}
+
+// Claims reports which of the votes table's unique claims a new vote takes.
+func (v VoteIdentity) Claims() (user, device bool) {
+	switch v {
+	case VoteIdentityDevice:
+		return false, true
+	case VoteIdentityUserDevice:
+		return true, true
+	default:
+		return true, false
+	}
+}
+
+func (v VoteIdentity) RequiresDevice() bool {
+	_, device := v.Claims()
+	return device
+}
+
+// OwnVote returns a SQL condition matching the caller's vote in the given
+// votes alias, appending the parameters it references to args.
+func (v VoteIdentity) OwnVote(alias, userID, deviceFingerprint string, args []interface{}) (string, []interface{}) {
+	args = append(args, userID)
+	cond := fmt.Sprintf("%s.user_id = $%d", alias, len(args))
+
+	if v == VoteIdentityDevice {
+		args = append(args, deviceFingerprint)
+		cond += fmt.Sprintf(" AND %s.device_fingerprint = $%d", alias, len(args))
+	}
+
+	return cond, args
+}
@@ -10,14 +10,16 @@ import (
 )
 
 type VoteHandler struct {
-	DB    *database.Database
-	WSHub *services.WebSocketHub
+	DB       *database.Database
+	WSHub    *services.WebSocketHub
+	Identity VoteIdentity
 }
 
-func NewVoteHandler(db *database.Database, wsHub *services.WebSocketHub) *VoteHandler {
+func NewVoteHandler(db *database.Database, wsHub *services.WebSocketHub, identity VoteIdentity) *VoteHandler {
 	return &VoteHandler{
-		DB:    db,
-		WSHub: wsHub,
+		DB:       db,
+		WSHub:    wsHub,
+		Identity: identity,
 	}
 }
 
@@ -45,71 +47,386 @@ func (h *VoteHandler) CreateVote(c *fiber.Ctx) error {
 	}
 
 	deviceFingerprint := c.Get("X-Device-Fingerprint", "")
-	if deviceFingerprint == "" {
+	if deviceFingerprint == "" && h.Identity.RequiresDevice() {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
 			Error: "Device fingerprint required",
 		})
 	}
 
-	var status string
-	err := h.DB.DB.QueryRow(`SELECT status FROM policies WHERE id = $1`, req.PolicyID).Scan(&status)
+	secret, code, msg := h.checkVotingOpen(req.PolicyID)
+	if code != 0 {
+		return c.Status(code).JSON(models.ErrorResponse{
+			Error: msg,
+		})
+	}
 
-	if err == sql.ErrNoRows {
-		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
-			Error: "Policy not found",
+	if secret {
+		return h.castSecretVote(c, req, userID, deviceFingerprint)
+	}
+
+	tx, err := h.DB.DB.Begin()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
 		})
 	}
+	defer tx.Rollback()
 
-	if status != "approved" && status != "uncertain" && status != "rejected" {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Error: "Can only vote on approved, uncertain, or rejected policies",
+	// Duplicate votes are rejected by the votes_one_per_user and
+	// votes_one_per_device unique indexes for whichever claims apply.
+	claimsUser, claimsDevice := h.Identity.Claims()
+	_, err = tx.Exec(`
+		INSERT INTO votes (policy_id, user_id, vote_type, device_fingerprint, claims_user, claims_device)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)
+	`, req.PolicyID, userID, req.VoteType, deviceFingerprint, claimsUser, claimsDevice)
+
+	if isUniqueViolation(err) {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Error: "You have already voted on this policy",
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to record vote",
+		})
+	}
+
+	if err := recordVoteHistory(tx, req.PolicyID, userID, "cast", nil, &req.VoteType, deviceFingerprint); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to record vote",
 		})
 	}
 
-	var deviceVoteCount int
-	err = h.DB.DB.QueryRow(`
-		SELECT COUNT(*) 
-		FROM votes 
-		WHERE policy_id = $1 AND device_fingerprint = $2
-	`, req.PolicyID, deviceFingerprint).Scan(&deviceVoteCount)
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to record vote",
+		})
+	}
+
+	h.broadcastTally(req.PolicyID)
 
-	if err != nil && err != sql.ErrNoRows {
+	return c.JSON(models.MessageResponse{
+		Message: "Vote recorded",
+	})
+}
+
+// castSecretVote records that the user took part and queues the choice in
+// secret_vote_queue in the same transaction, so neither is kept without
+// the other. The secret vote batcher writes it later with no user, device
+// or precise time among other votes, so it cannot be traced back to who
+// made it. Such votes cannot be changed or retracted.
+func (h *VoteHandler) castSecretVote(c *fiber.Ctx, req models.VoteRequest, userID, deviceFingerprint string) error {
+	tx, err := h.DB.DB.Begin()
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
 			Error: "Database error",
 		})
 	}
+	defer tx.Rollback()
+
+	claimsUser, claimsDevice := h.Identity.Claims()
+	_, err = tx.Exec(`
+		INSERT INTO vote_participation (policy_id, user_id, device_fingerprint, claims_user, claims_device)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5)
+	`, req.PolicyID, userID, deviceFingerprint, claimsUser, claimsDevice)
 
-	if deviceVoteCount > 0 {
+	if isUniqueViolation(err) {
 		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
 			Error: "You have already voted on this policy",
 		})
 	}
 
-	_, err = h.DB.DB.Exec(`
-		INSERT INTO votes (policy_id, user_id, vote_type, device_fingerprint)
-		VALUES ($1, $2, $3, $4)
-	`, req.PolicyID, userID, req.VoteType, deviceFingerprint)
-
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
 			Error: "Failed to record vote",
 		})
 	}
 
-	var upvotes, downvotes int
-	h.DB.DB.QueryRow(`
-		SELECT 
-			COALESCE(SUM(CASE WHEN vote_type = 'upvote' THEN 1 ELSE 0 END), 0) as upvotes,
-			COALESCE(SUM(CASE WHEN vote_type = 'downvote' THEN 1 ELSE 0 END), 0) as downvotes
-		FROM votes
-		WHERE policy_id = $1
-	`, req.PolicyID).Scan(&upvotes, &downvotes)
+	_, err = tx.Exec(`
+		INSERT INTO secret_vote_queue (policy_id, vote_type) VALUES ($1, $2)
+	`, req.PolicyID, req.VoteType)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to record vote",
+		})
+	}
 
-	if h.WSHub != nil {
-		h.WSHub.BroadcastVoteUpdate(req.PolicyID, upvotes, downvotes)
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to record vote",
+		})
 	}
 
 	return c.JSON(models.MessageResponse{
 		Message: "Vote recorded",
 	})
 }
+
+// PUT /api/v1/votes
+func (h *VoteHandler) UpdateVote(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	role := c.Locals("role").(string)
+
+	if role != "student" {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Error: "Only students can vote",
+		})
+	}
+
+	var req models.VoteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Invalid request body",
+		})
+	}
+
+	if req.VoteType != "upvote" && req.VoteType != "downvote" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Vote type must be upvote or downvote",
+		})
+	}
+
+	secret, code, msg := h.checkVotingOpen(req.PolicyID)
+	if code != 0 {
+		return c.Status(code).JSON(models.ErrorResponse{
+			Error: msg,
+		})
+	}
+
+	if secret {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Votes on a secret ballot cannot be changed",
+		})
+	}
+
+	deviceFingerprint := c.Get("X-Device-Fingerprint", "")
+
+	tx, err := h.DB.DB.Begin()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+	defer tx.Rollback()
+
+	ownVote, args := h.Identity.OwnVote("v", userID, deviceFingerprint, []interface{}{req.PolicyID})
+
+	var voteID, previousType string
+	err = tx.QueryRow(`
+		SELECT v.id, v.vote_type FROM votes v WHERE v.policy_id = $1 AND `+ownVote+` FOR UPDATE
+	`, args...).Scan(&voteID, &previousType)
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "You have not voted on this policy",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+
+	if previousType == req.VoteType {
+		return c.JSON(models.MessageResponse{
+			Message: "Vote unchanged",
+		})
+	}
+
+	_, err = tx.Exec(`
+		UPDATE votes SET vote_type = $1, updated_at = NOW() WHERE id = $2
+	`, req.VoteType, voteID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to change vote",
+		})
+	}
+
+	if err := recordVoteHistory(tx, req.PolicyID, userID, "change", &previousType, &req.VoteType, deviceFingerprint); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to change vote",
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to change vote",
+		})
+	}
+
+	h.broadcastTally(req.PolicyID)
+
+	return c.JSON(models.MessageResponse{
+		Message: "Vote changed",
+	})
+}
+
+// DELETE /api/v1/votes/:policyId
+func (h *VoteHandler) DeleteVote(c *fiber.Ctx) error {
+	policyID := c.Params("policyId")
+	userID := c.Locals("user_id").(string)
+	role := c.Locals("role").(string)
+
+	if role != "student" {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Error: "Only students can vote",
+		})
+	}
+
+	secret, code, msg := h.checkVotingOpen(policyID)
+	if code != 0 {
+		return c.Status(code).JSON(models.ErrorResponse{
+			Error: msg,
+		})
+	}
+
+	if secret {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error: "Votes on a secret ballot cannot be changed",
+		})
+	}
+
+	deviceFingerprint := c.Get("X-Device-Fingerprint", "")
+
+	tx, err := h.DB.DB.Begin()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Database error",
+		})
+	}
+	defer tx.Rollback()
+
+	ownVote, args := h.Identity.OwnVote("v", userID, deviceFingerprint, []interface{}{policyID})
+
+	var previousType string
+	err = tx.QueryRow(`
+		DELETE FROM votes v WHERE v.policy_id = $1 AND `+ownVote+`
+		RETURNING v.vote_type
+	`, args...).Scan(&previousType)
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error: "You have not voted on this policy",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to retract vote",
+		})
+	}
+
+	if err := recordVoteHistory(tx, policyID, userID, "retract", &previousType, nil, deviceFingerprint); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to retract vote",
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to retract vote",
+		})
+	}
+
+	h.broadcastTally(policyID)
+
+	return c.JSON(models.MessageResponse{
+		Message: "Vote retracted",
+	})
+}
+
+// GET /api/v1/admin/policies/:id/vote-history
+func (h *VoteHandler) GetVoteHistory(c *fiber.Ctx) error {
+	policyID := c.Params("id")
+
+	rows, err := h.DB.DB.Query(`
+		SELECT vh.id, vh.policy_id, vh.user_id, vh.action, vh.previous_type,
+			vh.new_type, vh.created_at, u.login_code
+		FROM vote_history vh
+		LEFT JOIN users u ON vh.user_id = u.id
+		WHERE vh.policy_id = $1
+		ORDER BY vh.created_at ASC
+	`, policyID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error: "Failed to fetch vote history",
+		})
+	}
+	defer rows.Close()
+
+	history := []models.VoteHistoryEntry{}
+	for rows.Next() {
+		var entry models.VoteHistoryEntry
+		var loginCode sql.NullString
+		err := rows.Scan(
+			&entry.ID, &entry.PolicyID, &entry.UserID, &entry.Action,
+			&entry.PreviousType, &entry.NewType, &entry.CreatedAt, &loginCode,
+		)
+		if err != nil {
+			continue
+		}
+		if loginCode.Valid {
+			entry.UserCode = &loginCode.String
+		}
+		history = append(history, entry)
+	}
+
+	return c.JSON(history)
+}
+
+// checkVotingOpen reports whether the policy uses a secret ballot, and returns
+// a non-zero status code and message when the policy does not exist or is not
+// currently accepting votes.
+func (h *VoteHandler) checkVotingOpen(policyID string) (bool, int, string) {
+	var status string
+	var secret, opened, notClosed bool
+	err := h.DB.DB.QueryRow(`
+		SELECT status, secret_ballot,
+			voting_opens_at IS NULL OR voting_opens_at <= NOW(),
+			voting_closes_at IS NULL OR voting_closes_at > NOW()
+		FROM policies WHERE id = $1
+	`, policyID).Scan(&status, &secret, &opened, &notClosed)
+
+	if err == sql.ErrNoRows {
+		return false, fiber.StatusNotFound, "Policy not found"
+	}
+	if err != nil {
+		return false, fiber.StatusInternalServerError, "Database error"
+	}
+
+	if status != "approved" && status != "uncertain" && status != "rejected" {
+		return secret, fiber.StatusBadRequest, "Can only vote on approved, uncertain, or rejected policies"
+	}
+
+	if !opened {
+		return secret, fiber.StatusBadRequest, "Voting has not opened yet"
+	}
+	if !notClosed {
+		return secret, fiber.StatusBadRequest, "Voting has closed"
+	}
+
+	return secret, 0, ""
+}
+
+// broadcastTally runs after every committed vote change and pushes the new
+// counts to clients. Cached lists are left alone: GetPolicies merges live
+// counts into them.
+func (h *VoteHandler) broadcastTally(policyID string) {
+	var upvotes, downvotes int
+	h.DB.DB.QueryRow(`SELECT upvotes, downvotes FROM policies WHERE id = $1`, policyID).Scan(&upvotes, &downvotes)
+
+	if h.WSHub != nil {
+		h.WSHub.BroadcastVoteUpdate(policyID, upvotes, downvotes)
+	}
+}
+
+func recordVoteHistory(tx *sql.Tx, policyID, userID, action string, previousType, newType *string, deviceFingerprint string) error {
+	var fingerprint *string
+	if deviceFingerprint != "" {
+		fingerprint = &deviceFingerprint
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO vote_history (policy_id, user_id, action, previous_type, new_type, device_fingerprint)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, policyID, userID, action, previousType, newType, fingerprint)
+	return err
+}
@@ -2,10 +2,12 @@ package middleware
 
 import (
 	"strings"
+	"time"
 	"vote/internal/models"
 	"vote/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
 )
 
 func AuthRequired(jwtSecret string) fiber.Handler {
@@ -39,6 +41,59 @@ func AuthRequired(jwtSecret string) fiber.Handler {
 	}
 }
 
+// WebSocketAuth authenticates a WebSocket upgrade. Browsers cannot set
+// headers on a WebSocket request, so the JWT is passed as the token query
+// parameter instead of an Authorization header.
+func WebSocketAuth(jwtSecret string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !websocket.IsWebSocketUpgrade(c) {
+			return fiber.ErrUpgradeRequired
+		}
+
+		claims, err := utils.ValidateJWT(c.Query("token"), jwtSecret)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+				Error: "Invalid or expired token",
+			})
+		}
+
+		c.Locals("user_id", claims.UserID)
+		c.Locals("role", claims.Role)
+		c.Locals("token_expires", tokenExpiry(claims))
+
+		return c.Next()
+	}
+}
+
+// EventStreamAuth authenticates a Server-Sent Events request. EventSource
+// cannot set headers either, so it also takes the JWT from the token query
+// parameter.
+func EventStreamAuth(jwtSecret string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, err := utils.ValidateJWT(c.Query("token"), jwtSecret)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+				Error: "Invalid or expired token",
+			})
+		}
+
+		c.Locals("user_id", claims.UserID)
+		c.Locals("role", claims.Role)
+		c.Locals("token_expires", tokenExpiry(claims))
+
+		return c.Next()
+	}
+}
+
+// tokenExpiry is when a long-lived connection opened with claims must be
+// closed. A token without an expiry gives the zero time, which never passes.
+func tokenExpiry(claims *utils.Claims) time.Time {
+	if claims.ExpiresAt == nil {
+		return time.Time{}
+	}
+	return claims.ExpiresAt.Time
+}
+
 func AdminRequired() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		role := c.Locals("role")
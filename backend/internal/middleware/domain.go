@@ -10,10 +10,6 @@ import (
 
 func DomainRestriction(cfg *config.Config) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		if c.Path() == "/ws" {
-			return c.Next()
-		}
-
 		origin := c.Get("Origin")
 		referer := c.Get("Referer")
 
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_policies_category_id;
+ALTER TABLE policies DROP COLUMN IF EXISTS category_id;
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name_en TEXT NOT NULL,
+    name_ro TEXT NOT NULL,
+    slug TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+ALTER TABLE policies ADD COLUMN IF NOT EXISTS category_id UUID REFERENCES categories(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_policies_category_id ON policies(category_id);
+
+INSERT INTO categories (name_en, name_ro, slug) VALUES
+('Academics', 'Academic', 'academics'),
+('Facilities', 'Facilități', 'facilities'),
+('Events', 'Evenimente', 'events'),
+('Food', 'Mâncare', 'food'),
+('Sports', 'Sport', 'sports'),
+('Other', 'Altele', 'other')
+ON CONFLICT (slug) DO NOTHING;
@@ -0,0 +1,7 @@
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('student', 'admin'));
+
+ALTER TABLE policies DROP CONSTRAINT IF EXISTS policies_status_check;
+ALTER TABLE policies ADD CONSTRAINT policies_status_check CHECK (status IN ('pending', 'approved', 'rejected', 'uncertain'));
+
+ALTER TABLE policies DROP COLUMN IF EXISTS view_count;
@@ -0,0 +1,10 @@
+ALTER TABLE policies ADD COLUMN IF NOT EXISTS view_count INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE policies DROP CONSTRAINT IF EXISTS policies_status_check;
+ALTER TABLE policies ADD CONSTRAINT policies_status_check CHECK (status IN (
+    'pending', 'approved', 'rejected', 'uncertain',
+    'in_progress', 'completed', 'on_hold', 'cannot_implement'
+));
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('student', 'admin', 'superuser'));
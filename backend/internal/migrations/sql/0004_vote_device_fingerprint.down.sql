@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_votes_policy_device;
+ALTER TABLE votes DROP COLUMN IF EXISTS device_fingerprint;
@@ -0,0 +1,2 @@
+ALTER TABLE votes ADD COLUMN IF NOT EXISTS device_fingerprint TEXT;
+CREATE INDEX IF NOT EXISTS idx_votes_policy_device ON votes(policy_id, device_fingerprint);
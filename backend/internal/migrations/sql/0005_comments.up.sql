@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS comments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    policy_id UUID NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    comment_text TEXT NOT NULL CHECK (char_length(comment_text) BETWEEN 1 AND 1000),
+    is_flagged BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_comments_policy_id ON comments(policy_id);
+CREATE INDEX IF NOT EXISTS idx_comments_user_id ON comments(user_id);
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS comment_revisions;
+
+DROP INDEX IF EXISTS idx_comments_moderation_status;
+DROP INDEX IF EXISTS idx_comments_thread;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS is_flagged;
+ALTER TABLE comments ADD COLUMN is_flagged BOOLEAN NOT NULL DEFAULT false;
+UPDATE comments SET is_flagged = true WHERE moderation_status <> 'visible';
+
+ALTER TABLE comments DROP COLUMN IF EXISTS moderation_status;
+ALTER TABLE comments DROP COLUMN IF EXISTS edited_at;
+ALTER TABLE comments DROP COLUMN IF EXISTS parent_id;
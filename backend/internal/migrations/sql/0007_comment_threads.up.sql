@@ -0,0 +1,23 @@
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES comments(id) ON DELETE CASCADE;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS moderation_status TEXT NOT NULL DEFAULT 'visible'
+    CHECK (moderation_status IN ('visible', 'held', 'hidden', 'removed'));
+
+UPDATE comments SET moderation_status = 'hidden' WHERE is_flagged = true;
+
+-- is_flagged is kept for existing readers but now follows the moderation state.
+ALTER TABLE comments DROP COLUMN is_flagged;
+ALTER TABLE comments ADD COLUMN is_flagged BOOLEAN GENERATED ALWAYS AS (moderation_status <> 'visible') STORED;
+
+CREATE INDEX IF NOT EXISTS idx_comments_thread ON comments(policy_id, parent_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_comments_moderation_status ON comments(moderation_status) WHERE moderation_status <> 'visible';
+
+CREATE TABLE IF NOT EXISTS comment_revisions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    comment_id UUID NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    comment_text TEXT NOT NULL,
+    edited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_revisions_comment_id ON comment_revisions(comment_id, created_at);
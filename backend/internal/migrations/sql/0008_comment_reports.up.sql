@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS comment_reports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    comment_id UUID NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL CHECK (char_length(reason) BETWEEN 1 AND 500),
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'resolved', 'dismissed')),
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP,
+    resolution_note TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(comment_id, reporter_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_reports_pending ON comment_reports(comment_id) WHERE status = 'pending';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS vote_history;
+DROP FUNCTION IF EXISTS vote_history_append_only();
+ALTER TABLE votes DROP COLUMN IF EXISTS updated_at;
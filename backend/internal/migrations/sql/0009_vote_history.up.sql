@@ -0,0 +1,27 @@
+ALTER TABLE votes ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP;
+
+-- Vote history deliberately has no foreign keys so the record survives the
+-- deletion of the policy, user or vote it describes.
+CREATE TABLE IF NOT EXISTS vote_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    policy_id UUID NOT NULL,
+    user_id UUID,
+    action TEXT NOT NULL CHECK (action IN ('cast', 'change', 'retract')),
+    previous_type TEXT CHECK (previous_type IN ('upvote', 'downvote')),
+    new_type TEXT CHECK (new_type IN ('upvote', 'downvote')),
+    device_fingerprint TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_vote_history_policy ON vote_history(policy_id, created_at);
+
+CREATE OR REPLACE FUNCTION vote_history_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'vote_history is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS vote_history_no_update ON vote_history;
+CREATE TRIGGER vote_history_no_update
+    BEFORE UPDATE OR DELETE ON vote_history
+    FOR EACH ROW EXECUTE FUNCTION vote_history_append_only();
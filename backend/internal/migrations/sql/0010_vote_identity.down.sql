@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS votes_one_per_device;
+DROP INDEX IF EXISTS votes_one_per_user;
+
+ALTER TABLE votes ADD CONSTRAINT votes_policy_id_user_id_key UNIQUE (policy_id, user_id);
+
+ALTER TABLE votes DROP COLUMN IF EXISTS claims_device;
+ALTER TABLE votes DROP COLUMN IF EXISTS claims_user;
@@ -0,0 +1,10 @@
+-- Which identities a vote claims is decided by the VOTE_IDENTITY setting at
+-- the time the vote is cast; the partial unique indexes enforce those claims
+-- atomically instead of relying on a check-then-insert in the handler.
+ALTER TABLE votes ADD COLUMN IF NOT EXISTS claims_user BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE votes ADD COLUMN IF NOT EXISTS claims_device BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS votes_policy_id_user_id_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS votes_one_per_user ON votes(policy_id, user_id) WHERE claims_user;
+CREATE UNIQUE INDEX IF NOT EXISTS votes_one_per_device ON votes(policy_id, device_fingerprint) WHERE claims_device;
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_policies_voting_closes_at;
+
+UPDATE policies SET status = 'uncertain' WHERE status = 'closed';
+
+ALTER TABLE policies DROP CONSTRAINT IF EXISTS policies_status_check;
+ALTER TABLE policies ADD CONSTRAINT policies_status_check CHECK (status IN (
+    'pending', 'approved', 'rejected', 'uncertain',
+    'in_progress', 'completed', 'on_hold', 'cannot_implement'
+));
+
+ALTER TABLE policies DROP CONSTRAINT IF EXISTS policies_voting_window_check;
+ALTER TABLE policies DROP COLUMN IF EXISTS voting_closes_at;
+ALTER TABLE policies DROP COLUMN IF EXISTS voting_opens_at;
@@ -0,0 +1,15 @@
+ALTER TABLE policies ADD COLUMN IF NOT EXISTS voting_opens_at TIMESTAMPTZ;
+ALTER TABLE policies ADD COLUMN IF NOT EXISTS voting_closes_at TIMESTAMPTZ;
+
+ALTER TABLE policies DROP CONSTRAINT IF EXISTS policies_voting_window_check;
+ALTER TABLE policies ADD CONSTRAINT policies_voting_window_check CHECK (
+    voting_opens_at IS NULL OR voting_closes_at IS NULL OR voting_closes_at > voting_opens_at
+);
+
+ALTER TABLE policies DROP CONSTRAINT IF EXISTS policies_status_check;
+ALTER TABLE policies ADD CONSTRAINT policies_status_check CHECK (status IN (
+    'pending', 'approved', 'rejected', 'uncertain', 'closed',
+    'in_progress', 'completed', 'on_hold', 'cannot_implement'
+));
+
+CREATE INDEX IF NOT EXISTS idx_policies_voting_closes_at ON policies(voting_closes_at) WHERE voting_closes_at IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS policy_decisions;
+DROP TABLE IF EXISTS decision_rules;
+
+ALTER TABLE policies DROP COLUMN IF EXISTS voting_closed_at;
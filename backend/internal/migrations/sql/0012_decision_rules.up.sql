@@ -0,0 +1,43 @@
+-- Records when the scheduler last closed voting so a status set afterwards,
+-- by an admin or an automatic decision, is not closed again.
+ALTER TABLE policies ADD COLUMN IF NOT EXISTS voting_closed_at TIMESTAMPTZ;
+
+-- A rule applies to a single policy, to every policy in a category, or (when
+-- both are NULL) globally; the most specific rule wins.
+CREATE TABLE IF NOT EXISTS decision_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    category_id UUID REFERENCES categories(id) ON DELETE CASCADE,
+    policy_id UUID REFERENCES policies(id) ON DELETE CASCADE,
+    min_turnout_percent NUMERIC(5, 2) CHECK (min_turnout_percent BETWEEN 0 AND 100),
+    min_approval_ratio NUMERIC(4, 3) CHECK (min_approval_ratio BETWEEN 0 AND 1),
+    min_net_votes INTEGER,
+    mode TEXT NOT NULL DEFAULT 'recommend' CHECK (mode IN ('recommend', 'automatic')),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    CHECK (category_id IS NULL OR policy_id IS NULL)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS decision_rules_global ON decision_rules((true)) WHERE category_id IS NULL AND policy_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS decision_rules_category ON decision_rules(category_id) WHERE category_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS decision_rules_policy ON decision_rules(policy_id) WHERE policy_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS policy_decisions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    policy_id UUID NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+    rule_id UUID REFERENCES decision_rules(id) ON DELETE SET NULL,
+    rule_snapshot JSONB NOT NULL,
+    outcome TEXT NOT NULL CHECK (outcome IN ('passed', 'failed', 'no_quorum')),
+    recommended_status TEXT NOT NULL,
+    applied BOOLEAN NOT NULL DEFAULT false,
+    upvotes INTEGER NOT NULL,
+    downvotes INTEGER NOT NULL,
+    voters INTEGER NOT NULL,
+    active_students INTEGER NOT NULL,
+    turnout_percent NUMERIC(6, 2) NOT NULL,
+    approval_ratio NUMERIC(4, 3) NOT NULL,
+    net_votes INTEGER NOT NULL,
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_policy_decisions_policy ON policy_decisions(policy_id, created_at DESC);
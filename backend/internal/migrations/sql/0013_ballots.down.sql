@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS ballot_choices;
+DROP TABLE IF EXISTS ballot_votes;
+DROP TABLE IF EXISTS ballot_options;
+DROP TABLE IF EXISTS ballots;
@@ -0,0 +1,48 @@
+-- A ballot groups competing options (usually existing policies) that are
+-- decided together by plurality, approval or instant-runoff voting.
+CREATE TABLE IF NOT EXISTS ballots (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    method TEXT NOT NULL CHECK (method IN ('plurality', 'approval', 'irv')),
+    max_choices INTEGER CHECK (max_choices > 0),
+    status TEXT NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'open', 'closed')),
+    closes_at TIMESTAMPTZ,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS ballot_options (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ballot_id UUID NOT NULL REFERENCES ballots(id) ON DELETE CASCADE,
+    policy_id UUID REFERENCES policies(id) ON DELETE SET NULL,
+    label TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    UNIQUE (ballot_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ballot_options_ballot ON ballot_options(ballot_id);
+
+-- One row per submitted ballot; identity claims mirror the votes table.
+CREATE TABLE IF NOT EXISTS ballot_votes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ballot_id UUID NOT NULL REFERENCES ballots(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_fingerprint TEXT,
+    claims_user BOOLEAN NOT NULL DEFAULT true,
+    claims_device BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ballot_votes_one_per_user ON ballot_votes(ballot_id, user_id) WHERE claims_user;
+CREATE UNIQUE INDEX IF NOT EXISTS ballot_votes_one_per_device ON ballot_votes(ballot_id, device_fingerprint) WHERE claims_device;
+
+-- The options chosen on a ballot in preference order; rank 1 is the first
+-- choice. Plurality ballots hold one choice, approval ballots are unordered.
+CREATE TABLE IF NOT EXISTS ballot_choices (
+    ballot_vote_id UUID NOT NULL REFERENCES ballot_votes(id) ON DELETE CASCADE,
+    option_id UUID NOT NULL REFERENCES ballot_options(id) ON DELETE CASCADE,
+    rank INTEGER NOT NULL CHECK (rank > 0),
+    PRIMARY KEY (ballot_vote_id, option_id),
+    UNIQUE (ballot_vote_id, rank)
+);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS vote_participation;
+
+-- Anonymous votes cannot be attributed back to a user, so they are dropped.
+DELETE FROM votes WHERE user_id IS NULL;
+
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS votes_identity_check;
+ALTER TABLE votes ALTER COLUMN user_id SET NOT NULL;
+
+ALTER TABLE policies DROP COLUMN IF EXISTS secret_ballot;
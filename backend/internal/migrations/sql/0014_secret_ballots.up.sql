@@ -0,0 +1,26 @@
+ALTER TABLE policies ADD COLUMN IF NOT EXISTS secret_ballot BOOLEAN NOT NULL DEFAULT false;
+
+-- Votes on a secret ballot are stored without a user or device; only the
+-- fact that someone voted is kept, in vote_participation.
+ALTER TABLE votes ALTER COLUMN user_id DROP NOT NULL;
+
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS votes_identity_check;
+ALTER TABLE votes ADD CONSTRAINT votes_identity_check CHECK (
+    user_id IS NOT NULL OR (device_fingerprint IS NULL AND NOT claims_user AND NOT claims_device)
+);
+
+-- Identity claims mirror the votes table so secret ballots enforce the same
+-- one-vote rule as open ones.
+CREATE TABLE IF NOT EXISTS vote_participation (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    policy_id UUID NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_fingerprint TEXT,
+    claims_user BOOLEAN NOT NULL DEFAULT true,
+    claims_device BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS vote_participation_one_per_user ON vote_participation(policy_id, user_id) WHERE claims_user;
+CREATE UNIQUE INDEX IF NOT EXISTS vote_participation_one_per_device ON vote_participation(policy_id, device_fingerprint) WHERE claims_device;
+CREATE INDEX IF NOT EXISTS idx_vote_participation_user ON vote_participation(user_id);
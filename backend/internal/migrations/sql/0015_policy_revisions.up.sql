@@ -0,0 +1,21 @@
+-- Every version of a policy's text. Revision 1 is the submission (or, for
+-- policies that predate this table, their text at migration time); each
+-- edit or restore adds the resulting version.
+CREATE TABLE IF NOT EXISTS policy_revisions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    policy_id UUID NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL,
+    category_id UUID REFERENCES categories(id) ON DELETE SET NULL,
+    action TEXT NOT NULL CHECK (action IN ('baseline', 'create', 'edit', 'restore')),
+    restored_from INTEGER,
+    edited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (policy_id, revision)
+);
+
+INSERT INTO policy_revisions (policy_id, revision, title, description, category_id, action)
+SELECT id, 1, title, description, category_id, 'baseline'
+FROM policies
+WHERE NOT EXISTS (SELECT 1 FROM policy_revisions r WHERE r.policy_id = policies.id);
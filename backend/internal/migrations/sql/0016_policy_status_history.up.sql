@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS policy_status_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    policy_id UUID NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+    from_status TEXT,
+    to_status TEXT NOT NULL,
+    comment TEXT,
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_policy_status_history_policy ON policy_status_history(policy_id, created_at);
+
+-- Start every existing policy's timeline at its current status. Only a
+-- pending policy is known to have had that status since submission.
+INSERT INTO policy_status_history (policy_id, from_status, to_status, changed_by, created_at)
+SELECT id, NULL, status,
+    CASE WHEN status = 'pending' THEN submitted_by END,
+    CASE WHEN status = 'pending' THEN created_at ELSE NOW() END
+FROM policies
+WHERE NOT EXISTS (SELECT 1 FROM policy_status_history h WHERE h.policy_id = policies.id);
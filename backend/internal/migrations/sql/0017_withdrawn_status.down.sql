@@ -0,0 +1,9 @@
+-- A withdrawn submission was never reviewed, so it goes back to the
+-- reviewers' queue rather than being given an outcome.
+UPDATE policies SET status = 'pending' WHERE status = 'withdrawn';
+
+ALTER TABLE policies DROP CONSTRAINT IF EXISTS policies_status_check;
+ALTER TABLE policies ADD CONSTRAINT policies_status_check CHECK (status IN (
+    'pending', 'approved', 'rejected', 'uncertain', 'closed',
+    'in_progress', 'completed', 'on_hold', 'cannot_implement'
+));
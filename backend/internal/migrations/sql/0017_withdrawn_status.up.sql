@@ -0,0 +1,5 @@
+ALTER TABLE policies DROP CONSTRAINT IF EXISTS policies_status_check;
+ALTER TABLE policies ADD CONSTRAINT policies_status_check CHECK (status IN (
+    'pending', 'approved', 'rejected', 'uncertain', 'closed',
+    'in_progress', 'completed', 'on_hold', 'cannot_implement', 'withdrawn'
+));
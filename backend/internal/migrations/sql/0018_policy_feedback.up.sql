@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS policy_feedback (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    policy_id UUID NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+    author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('message', 'changes_requested', 'resubmitted', 'published')),
+    body TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT policy_feedback_body_check CHECK (kind = 'resubmitted' OR length(body) > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_policy_feedback_policy ON policy_feedback(policy_id, created_at);
+
+-- Carry each existing admin comment over as the policy's published message.
+-- Who wrote it was never recorded.
+INSERT INTO policy_feedback (policy_id, kind, body)
+SELECT id, 'published', admin_comment
+FROM policies
+WHERE admin_comment IS NOT NULL AND admin_comment <> ''
+    AND NOT EXISTS (SELECT 1 FROM policy_feedback f WHERE f.policy_id = policies.id);
@@ -0,0 +1,24 @@
+-- vote_history is append-only; map merge entries onto the plain actions
+-- they amount to so the original constraint can be restored.
+ALTER TABLE vote_history DISABLE TRIGGER vote_history_no_update;
+UPDATE vote_history SET action = 'retract' WHERE action = 'merge_out';
+UPDATE vote_history SET action = 'cast' WHERE action = 'merge_in';
+ALTER TABLE vote_history ENABLE TRIGGER vote_history_no_update;
+
+ALTER TABLE vote_history DROP CONSTRAINT IF EXISTS vote_history_action_check;
+ALTER TABLE vote_history ADD CONSTRAINT vote_history_action_check CHECK (
+    action IN ('cast', 'change', 'retract')
+);
+
+UPDATE policies SET status = 'closed' WHERE status = 'merged';
+
+ALTER TABLE policies DROP CONSTRAINT IF EXISTS policies_status_check;
+ALTER TABLE policies ADD CONSTRAINT policies_status_check CHECK (status IN (
+    'pending', 'approved', 'rejected', 'uncertain', 'closed',
+    'in_progress', 'completed', 'on_hold', 'cannot_implement', 'withdrawn'
+));
+
+ALTER TABLE policies DROP COLUMN IF EXISTS merged_into;
+
+DROP INDEX IF EXISTS idx_policies_description_trgm;
+DROP INDEX IF EXISTS idx_policies_title_trgm;
@@ -0,0 +1,19 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_policies_title_trgm ON policies USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_policies_description_trgm ON policies USING GIN (description gin_trgm_ops);
+
+-- A merged policy keeps its row so links and vote history still resolve; its
+-- votes and comments live on the policy it was merged into.
+ALTER TABLE policies ADD COLUMN IF NOT EXISTS merged_into UUID REFERENCES policies(id) ON DELETE SET NULL;
+
+ALTER TABLE policies DROP CONSTRAINT IF EXISTS policies_status_check;
+ALTER TABLE policies ADD CONSTRAINT policies_status_check CHECK (status IN (
+    'pending', 'approved', 'rejected', 'uncertain', 'closed',
+    'in_progress', 'completed', 'on_hold', 'cannot_implement', 'withdrawn', 'merged'
+));
+
+ALTER TABLE vote_history DROP CONSTRAINT IF EXISTS vote_history_action_check;
+ALTER TABLE vote_history ADD CONSTRAINT vote_history_action_check CHECK (
+    action IN ('cast', 'change', 'retract', 'merge_out', 'merge_in')
+);
@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS idx_policies_split_from;
+DROP INDEX IF EXISTS idx_policies_merged_into;
+
+-- Merge snapshots become plain edits, and a split part's first revision
+-- becomes its submission.
+UPDATE policy_revisions SET action = 'edit' WHERE action = 'merge';
+UPDATE policy_revisions SET action = 'create' WHERE action = 'split';
+
+ALTER TABLE policy_revisions DROP CONSTRAINT IF EXISTS policy_revisions_action_check;
+ALTER TABLE policy_revisions ADD CONSTRAINT policy_revisions_action_check CHECK (
+    action IN ('baseline', 'create', 'edit', 'restore')
+);
+
+ALTER TABLE policy_revisions DROP COLUMN IF EXISTS origin_policy_id;
+ALTER TABLE policies DROP COLUMN IF EXISTS split_from;
@@ -0,0 +1,13 @@
+-- A split part points back at the policy it was cut from.
+ALTER TABLE policies ADD COLUMN IF NOT EXISTS split_from UUID REFERENCES policies(id) ON DELETE SET NULL;
+
+-- Revisions moved onto a policy by a merge remember where they came from.
+ALTER TABLE policy_revisions ADD COLUMN IF NOT EXISTS origin_policy_id UUID REFERENCES policies(id) ON DELETE SET NULL;
+
+ALTER TABLE policy_revisions DROP CONSTRAINT IF EXISTS policy_revisions_action_check;
+ALTER TABLE policy_revisions ADD CONSTRAINT policy_revisions_action_check CHECK (
+    action IN ('baseline', 'create', 'edit', 'restore', 'merge', 'split')
+);
+
+CREATE INDEX IF NOT EXISTS idx_policies_merged_into ON policies(merged_into) WHERE merged_into IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_policies_split_from ON policies(split_from) WHERE split_from IS NOT NULL;
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_comments_search_ro;
+DROP INDEX IF EXISTS idx_comments_search_en;
+DROP INDEX IF EXISTS idx_policies_search_ro;
+DROP INDEX IF EXISTS idx_policies_search_en;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS search_ro;
+ALTER TABLE comments DROP COLUMN IF EXISTS search_en;
+ALTER TABLE policies DROP COLUMN IF EXISTS search_ro;
+ALTER TABLE policies DROP COLUMN IF EXISTS search_en;
+
+DROP TEXT SEARCH CONFIGURATION IF EXISTS vote_ro;
+DROP TEXT SEARCH CONFIGURATION IF EXISTS vote_en;
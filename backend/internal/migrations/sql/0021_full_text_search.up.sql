@@ -0,0 +1,42 @@
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- Text search configurations that fold diacritics before stemming, so
+-- "scoala" and "școală" reach the same lexeme.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'vote_en') THEN
+        CREATE TEXT SEARCH CONFIGURATION vote_en (COPY = english);
+        ALTER TEXT SEARCH CONFIGURATION vote_en
+            ALTER MAPPING FOR hword, hword_part, word WITH unaccent, english_stem;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'vote_ro') THEN
+        CREATE TEXT SEARCH CONFIGURATION vote_ro (COPY = romanian);
+        ALTER TEXT SEARCH CONFIGURATION vote_ro
+            ALTER MAPPING FOR hword, hword_part, word WITH unaccent, romanian_stem;
+    END IF;
+END
+$$;
+
+ALTER TABLE policies ADD COLUMN IF NOT EXISTS search_en tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('vote_en'::regconfig, coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('vote_en'::regconfig, coalesce(description, '')), 'B')
+) STORED;
+
+ALTER TABLE policies ADD COLUMN IF NOT EXISTS search_ro tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('vote_ro'::regconfig, coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('vote_ro'::regconfig, coalesce(description, '')), 'B')
+) STORED;
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS search_en tsvector GENERATED ALWAYS AS (
+    to_tsvector('vote_en'::regconfig, comment_text)
+) STORED;
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS search_ro tsvector GENERATED ALWAYS AS (
+    to_tsvector('vote_ro'::regconfig, comment_text)
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_policies_search_en ON policies USING GIN (search_en);
+CREATE INDEX IF NOT EXISTS idx_policies_search_ro ON policies USING GIN (search_ro);
+CREATE INDEX IF NOT EXISTS idx_comments_search_en ON comments USING GIN (search_en);
+CREATE INDEX IF NOT EXISTS idx_comments_search_ro ON comments USING GIN (search_ro);
@@ -0,0 +1 @@
+DROP MATERIALIZED VIEW IF EXISTS policy_scores;
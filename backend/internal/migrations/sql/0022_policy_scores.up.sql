@@ -0,0 +1,32 @@
+-- Sort scores for the trending and controversial listings. Both scan every
+-- vote, so they are kept in a materialized view that ScoreRefresher
+-- refreshes periodically instead of being computed per request.
+--
+-- hot_score is decayed vote velocity: each vote cast or changed in the last
+-- seven days counts 1, halving every 24 hours after it was cast.
+--
+-- controversy is (up + down) ^ (minority / majority): high when a policy has
+-- many votes split close to evenly, zero when every vote agrees.
+CREATE MATERIALIZED VIEW IF NOT EXISTS policy_scores AS
+SELECT
+    p.id AS policy_id,
+    COALESCE(SUM(POWER(0.5, EXTRACT(EPOCH FROM NOW() - COALESCE(v.updated_at, v.created_at)) / 86400.0))
+        FILTER (WHERE COALESCE(v.updated_at, v.created_at) > NOW() - INTERVAL '7 days'), 0)::double precision AS hot_score,
+    CASE
+        WHEN COUNT(*) FILTER (WHERE v.vote_type = 'upvote') = 0
+            OR COUNT(*) FILTER (WHERE v.vote_type = 'downvote') = 0 THEN 0
+        ELSE POWER(
+            COUNT(v.id)::double precision,
+            LEAST(COUNT(*) FILTER (WHERE v.vote_type = 'upvote'), COUNT(*) FILTER (WHERE v.vote_type = 'downvote'))::double precision
+                / GREATEST(COUNT(*) FILTER (WHERE v.vote_type = 'upvote'), COUNT(*) FILTER (WHERE v.vote_type = 'downvote'))
+        )
+    END::double precision AS controversy,
+    NOW() AS scored_at
+FROM policies p
+LEFT JOIN votes v ON v.policy_id = p.id
+GROUP BY p.id;
+
+-- REFRESH ... CONCURRENTLY needs a unique index.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_policy_scores_policy ON policy_scores(policy_id);
+CREATE INDEX IF NOT EXISTS idx_policy_scores_hot ON policy_scores(hot_score DESC, policy_id DESC);
+CREATE INDEX IF NOT EXISTS idx_policy_scores_controversy ON policy_scores(controversy DESC, policy_id DESC);
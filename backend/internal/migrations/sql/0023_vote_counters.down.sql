@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_policies_total_votes;
+
+DROP TRIGGER IF EXISTS votes_count ON votes;
+DROP FUNCTION IF EXISTS policies_count_votes();
+
+ALTER TABLE policies DROP COLUMN IF EXISTS downvotes;
+ALTER TABLE policies DROP COLUMN IF EXISTS upvotes;
@@ -0,0 +1,46 @@
+-- Denormalized vote counts, kept in step with the votes table by trigger so
+-- that every write path (casting, changing, retracting, merging, cascading
+-- deletes) updates them in the same transaction. VoteCounterReconciler
+-- checks them against the votes table and repairs any drift.
+ALTER TABLE policies ADD COLUMN IF NOT EXISTS upvotes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE policies ADD COLUMN IF NOT EXISTS downvotes INTEGER NOT NULL DEFAULT 0;
+
+UPDATE policies p SET
+    upvotes = counts.upvotes,
+    downvotes = counts.downvotes
+FROM (
+    SELECT
+        policy_id,
+        COUNT(*) FILTER (WHERE vote_type = 'upvote') AS upvotes,
+        COUNT(*) FILTER (WHERE vote_type = 'downvote') AS downvotes
+    FROM votes
+    GROUP BY policy_id
+) counts
+WHERE p.id = counts.policy_id;
+
+CREATE OR REPLACE FUNCTION policies_count_votes() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('DELETE', 'UPDATE') THEN
+        UPDATE policies SET
+            upvotes = upvotes - (OLD.vote_type = 'upvote')::int,
+            downvotes = downvotes - (OLD.vote_type = 'downvote')::int
+        WHERE id = OLD.policy_id;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE policies SET
+            upvotes = upvotes + (NEW.vote_type = 'upvote')::int,
+            downvotes = downvotes + (NEW.vote_type = 'downvote')::int
+        WHERE id = NEW.policy_id;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS votes_count ON votes;
+CREATE TRIGGER votes_count
+    AFTER INSERT OR DELETE OR UPDATE OF vote_type, policy_id ON votes
+    FOR EACH ROW EXECUTE FUNCTION policies_count_votes();
+
+CREATE INDEX IF NOT EXISTS idx_policies_total_votes ON policies((upvotes + downvotes) DESC, id DESC);
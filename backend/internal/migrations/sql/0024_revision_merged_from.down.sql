@@ -0,0 +1 @@
+ALTER TABLE policy_revisions DROP COLUMN IF EXISTS merged_from;
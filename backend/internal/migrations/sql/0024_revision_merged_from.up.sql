@@ -0,0 +1,8 @@
+-- Revisions moved onto a policy by a merge record the policy they were
+-- merged from. They stay in the target's history for reference but are
+-- never restored or diffed as the target's own text. The column has no
+-- foreign key so the mark survives the source being deleted.
+ALTER TABLE policy_revisions ADD COLUMN IF NOT EXISTS merged_from UUID;
+
+UPDATE policy_revisions SET merged_from = origin_policy_id
+WHERE origin_policy_id IS NOT NULL AND merged_from IS NULL;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_policies_total_votes ON policies((upvotes + downvotes) DESC, id DESC);
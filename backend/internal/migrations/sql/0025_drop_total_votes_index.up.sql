@@ -0,0 +1,5 @@
+-- The expression index on upvotes + downvotes made every vote a non-HOT
+-- update of policies, rewriting its full-text GIN indexes each time. The
+-- most voted sort runs over the already filtered policy list, so it sorts
+-- on the live counters without an index.
+DROP INDEX IF EXISTS idx_policies_total_votes;
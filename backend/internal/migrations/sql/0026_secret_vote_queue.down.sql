@@ -0,0 +1,8 @@
+ALTER TABLE vote_participation ALTER COLUMN created_at SET DEFAULT NOW();
+
+-- Choices still waiting are written as votes rather than lost.
+INSERT INTO votes (policy_id, user_id, vote_type, claims_user, claims_device, created_at)
+SELECT policy_id, NULL, vote_type, false, false, date_trunc('day', NOW())
+FROM secret_vote_queue;
+
+DROP TABLE IF EXISTS secret_vote_queue;
@@ -0,0 +1,16 @@
+-- Secret ballot choices wait here, committed with the voter's participation
+-- record, until SecretVoteBatcher moves them into votes in shuffled
+-- batches. The table holds no user, device or time, and its rows are
+-- deleted once moved.
+CREATE TABLE IF NOT EXISTS secret_vote_queue (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    policy_id UUID NOT NULL REFERENCES policies(id) ON DELETE CASCADE,
+    vote_type TEXT NOT NULL CHECK (vote_type IN ('upvote', 'downvote'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_secret_vote_queue_policy ON secret_vote_queue(policy_id);
+
+-- A precise participation time could be matched against the moment a
+-- batch changed the tally, so only the day is kept.
+UPDATE vote_participation SET created_at = date_trunc('day', created_at);
+ALTER TABLE vote_participation ALTER COLUMN created_at SET DEFAULT date_trunc('day', NOW());
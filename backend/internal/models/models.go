@@ -33,6 +33,17 @@ type Vote struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type VoteHistoryEntry struct {
+	ID           string    `json:"id"`
+	PolicyID     string    `json:"policy_id"`
+	UserID       *string   `json:"user_id,omitempty"`
+	UserCode     *string   `json:"user_code,omitempty"`
+	Action       string    `json:"action"`
+	PreviousType *string   `json:"previous_type"`
+	NewType      *string   `json:"new_type"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
 // Request/Response DTOs
 type CodeLoginRequest struct {
 	Code string `json:"code"`
@@ -85,11 +96,23 @@ type Category struct {
 }
 
 type Comment struct {
+	ID               string     `json:"id"`
+	PolicyID         string     `json:"policy_id"`
+	ParentID         *string    `json:"parent_id,omitempty"`
+	UserID           string     `json:"user_id"`
+	CommentText      string     `json:"comment_text"`
+	IsFlagged        bool       `json:"is_flagged"`
+	ModerationStatus string     `json:"moderation_status"`
+	ReplyCount       int        `json:"reply_count"`
+	EditedAt         *time.Time `json:"edited_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+type CommentRevision struct {
 	ID          string    `json:"id"`
-	PolicyID    string    `json:"policy_id"`
-	UserID      string    `json:"user_id"`
+	CommentID   string    `json:"comment_id"`
 	CommentText string    `json:"comment_text"`
-	IsFlagged   bool      `json:"is_flagged"`
+	EditedBy    *string   `json:"edited_by,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 }
 
@@ -106,20 +129,59 @@ type AuditLogEntry struct {
 // Update Policy struct to include new fields
 type PolicyExtended struct {
 	Policy
-	CategoryID          *string `json:"category_id,omitempty"`
-	CategoryName        *string `json:"category_name,omitempty"`
-	ImplementationDate  *string `json:"implementation_date,omitempty"`
-	EstimatedCompletion *string `json:"estimated_completion,omitempty"`
-	ViewCount           int     `json:"view_count"`
-	CommentCount        int     `json:"comment_count,omitempty"`
+	CategoryID          *string    `json:"category_id,omitempty"`
+	CategoryName        *string    `json:"category_name,omitempty"`
+	ImplementationDate  *string    `json:"implementation_date,omitempty"`
+	EstimatedCompletion *string    `json:"estimated_completion,omitempty"`
+	ViewCount           int        `json:"view_count"`
+	CommentCount        int        `json:"comment_count,omitempty"`
+	VotingOpensAt       *time.Time `json:"voting_opens_at,omitempty"`
+	VotingClosesAt      *time.Time `json:"voting_closes_at,omitempty"`
+	SecretBallot        bool       `json:"secret_ballot"`
 }
 
 // Request DTOs
 type CreateCommentRequest struct {
-	PolicyID    string `json:"policy_id"`
+	PolicyID    string  `json:"policy_id"`
+	ParentID    *string `json:"parent_id,omitempty"`
+	CommentText string  `json:"comment_text"`
+}
+
+type UpdateCommentRequest struct {
 	CommentText string `json:"comment_text"`
 }
 
+type ModerateCommentRequest struct {
+	Status string  `json:"status"`
+	Reason *string `json:"reason,omitempty"`
+}
+
+type ReportCommentRequest struct {
+	Reason string `json:"reason"`
+}
+
+type ResolveReportRequest struct {
+	Action string  `json:"action"`
+	Note   *string `json:"note,omitempty"`
+}
+
+type CommentReportSummary struct {
+	CommentID        string    `json:"comment_id"`
+	PolicyID         string    `json:"policy_id"`
+	CommentText      string    `json:"comment_text"`
+	ModerationStatus string    `json:"moderation_status"`
+	ReportCount      int       `json:"report_count"`
+	Reasons          []string  `json:"reasons"`
+	FirstReportedAt  time.Time `json:"first_reported_at"`
+	LastReportedAt   time.Time `json:"last_reported_at"`
+}
+
+type CommentPage struct {
+	Comments   []Comment `json:"comments"`
+	NextCursor *string   `json:"next_cursor"`
+	Total      *int      `json:"total,omitempty"`
+}
+
 type UpdatePolicyExtendedRequest struct {
 	Status              string  `json:"status"`
 	Comment             *string `json:"comment,omitempty"`
@@ -164,3 +226,217 @@ type CategoryStats struct {
 	PolicyCount  int    `json:"policy_count"`
 	VoteCount    int    `json:"vote_count"`
 }
+
+type DecisionRule struct {
+	ID                string    `json:"id"`
+	CategoryID        *string   `json:"category_id"`
+	PolicyID          *string   `json:"policy_id"`
+	MinTurnoutPercent *float64  `json:"min_turnout_percent"`
+	MinApprovalRatio  *float64  `json:"min_approval_ratio"`
+	MinNetVotes       *int      `json:"min_net_votes"`
+	Mode              string    `json:"mode"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type PolicyDecision struct {
+	ID                string          `json:"id"`
+	PolicyID          string          `json:"policy_id"`
+	RuleID            *string         `json:"rule_id"`
+	Rule              json.RawMessage `json:"rule"`
+	Outcome           string          `json:"outcome"`
+	RecommendedStatus string          `json:"recommended_status"`
+	Applied           bool            `json:"applied"`
+	Upvotes           int             `json:"upvotes"`
+	Downvotes         int             `json:"downvotes"`
+	Voters            int             `json:"voters"`
+	ActiveStudents    int             `json:"active_students"`
+	TurnoutPercent    float64         `json:"turnout_percent"`
+	ApprovalRatio     float64         `json:"approval_ratio"`
+	NetVotes          int             `json:"net_votes"`
+	DecidedBy         *string         `json:"decided_by"`
+	CreatedAt         time.Time       `json:"created_at"`
+}
+
+type DecisionRuleRequest struct {
+	CategoryID        *string  `json:"category_id,omitempty"`
+	PolicyID          *string  `json:"policy_id,omitempty"`
+	MinTurnoutPercent *float64 `json:"min_turnout_percent,omitempty"`
+	MinApprovalRatio  *float64 `json:"min_approval_ratio,omitempty"`
+	MinNetVotes       *int     `json:"min_net_votes,omitempty"`
+	Mode              string   `json:"mode"`
+}
+
+type EvaluatePolicyRequest struct {
+	Apply bool `json:"apply"`
+}
+
+type Ballot struct {
+	ID          string         `json:"id"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Method      string         `json:"method"`
+	MaxChoices  *int           `json:"max_choices"`
+	Status      string         `json:"status"`
+	ClosesAt    *time.Time     `json:"closes_at"`
+	CreatedBy   *string        `json:"created_by,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	Options     []BallotOption `json:"options,omitempty"`
+	TotalVotes  int            `json:"total_votes"`
+	HasVoted    bool           `json:"has_voted"`
+}
+
+type BallotOption struct {
+	ID       string  `json:"id"`
+	PolicyID *string `json:"policy_id"`
+	Label    string  `json:"label"`
+	Position int     `json:"position"`
+}
+
+// BallotOptionCount is an option's vote count in one round of a tally.
+type BallotOptionCount struct {
+	OptionID string `json:"option_id"`
+	Label    string `json:"label"`
+	Votes    int    `json:"votes"`
+}
+
+// BallotRound is one counting round. TieBreak names the rule that chose
+// the eliminated option when several were tied for last: "previous_round"
+// or "ballot_order".
+type BallotRound struct {
+	Round      int                 `json:"round"`
+	Counts     []BallotOptionCount `json:"counts"`
+	Exhausted  int                 `json:"exhausted"`
+	Eliminated []string            `json:"eliminated"`
+	TieBreak   string              `json:"tie_break,omitempty"`
+}
+
+// BallotResult is the outcome of a tally. Plurality and approval tallies
+// have a single round; instant-runoff has one round per elimination. More
+// than one winner means a tie.
+type BallotResult struct {
+	BallotID     string        `json:"ballot_id"`
+	Method       string        `json:"method"`
+	TotalBallots int           `json:"total_ballots"`
+	Rounds       []BallotRound `json:"rounds"`
+	Winners      []string      `json:"winners"`
+}
+
+type BallotOptionRequest struct {
+	PolicyID *string `json:"policy_id,omitempty"`
+	Label    string  `json:"label"`
+}
+
+type CreateBallotRequest struct {
+	Title       string                `json:"title"`
+	Description string                `json:"description"`
+	Method      string                `json:"method"`
+	MaxChoices  *int                  `json:"max_choices,omitempty"`
+	ClosesAt    *time.Time            `json:"closes_at,omitempty"`
+	Options     []BallotOptionRequest `json:"options"`
+}
+
+type BallotStatusRequest struct {
+	Status string `json:"status"`
+}
+
+// BallotVoteRequest lists option IDs in preference order. Plurality takes
+// exactly one; approval takes any set up to the ballot's max_choices.
+type BallotVoteRequest struct {
+	Choices []string `json:"choices"`
+}
+
+type PolicyRevision struct {
+	ID             string    `json:"id"`
+	PolicyID       string    `json:"policy_id"`
+	Revision       int       `json:"revision"`
+	Title          string    `json:"title"`
+	Description    string    `json:"description"`
+	CategoryID     *string   `json:"category_id"`
+	Action         string    `json:"action"`
+	RestoredFrom   *int      `json:"restored_from,omitempty"`
+	OriginPolicyID *string   `json:"origin_policy_id,omitempty"`
+	MergedFrom     *string   `json:"merged_from,omitempty"`
+	EditedBy       *string   `json:"edited_by,omitempty"`
+	EditorRole     *string   `json:"editor_role,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type PolicyStatusChange struct {
+	ID         string    `json:"id"`
+	PolicyID   string    `json:"policy_id"`
+	FromStatus *string   `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Comment    *string   `json:"comment,omitempty"`
+	ChangedBy  *string   `json:"changed_by,omitempty"`
+	ActorRole  *string   `json:"actor_role,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// PolicyFeedback is one message in the private review thread between
+// reviewers and a policy's author. A published message is also shown
+// publicly as the policy's admin comment.
+type PolicyFeedback struct {
+	ID         string    `json:"id"`
+	PolicyID   string    `json:"policy_id"`
+	AuthorID   *string   `json:"author_id,omitempty"`
+	AuthorRole *string   `json:"author_role,omitempty"`
+	Kind       string    `json:"kind"`
+	Body       string    `json:"body"`
+	Mine       bool      `json:"mine"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type FeedbackRequest struct {
+	Body string `json:"body"`
+	Kind string `json:"kind"`
+}
+
+// SimilarPolicy is an existing policy that looks like a duplicate of a new
+// submission, scored by trigram similarity between 0 and 1.
+type SimilarPolicy struct {
+	ID         string  `json:"id"`
+	Title      string  `json:"title"`
+	Status     string  `json:"status"`
+	Upvotes    int     `json:"upvotes"`
+	Downvotes  int     `json:"downvotes"`
+	Similarity float64 `json:"similarity"`
+}
+
+// MergeResult summarises what a merge moved onto the surviving policy.
+type MergeResult struct {
+	SourceID       string   `json:"source_id"`
+	TargetID       string   `json:"target_id"`
+	VotesMoved     int      `json:"votes_moved"`
+	VotesDropped   int      `json:"votes_dropped"`
+	CommentsMoved  int      `json:"comments_moved"`
+	RevisionsMoved int      `json:"revisions_moved"`
+	PreviousStatus string   `json:"previous_status"`
+	CommentIDs     []string `json:"-"`
+}
+
+type MergePoliciesRequest struct {
+	SourceIDs []string `json:"source_ids"`
+}
+
+// SplitPart is one policy cut from another. A nil category keeps the
+// original policy's category.
+type SplitPart struct {
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	CategoryID  *string `json:"category_id"`
+}
+
+type SplitPolicyRequest struct {
+	Parts []SplitPart `json:"parts"`
+}
+
+// VoteCounterRepair records a policy whose stored vote counters had drifted
+// from its votes, with the stored and recounted values.
+type VoteCounterRepair struct {
+	PolicyID        string `json:"policy_id"`
+	StoredUpvotes   int    `json:"stored_upvotes"`
+	StoredDownvotes int    `json:"stored_downvotes"`
+	Upvotes         int    `json:"upvotes"`
+	Downvotes       int    `json:"downvotes"`
+}
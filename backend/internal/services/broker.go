@@ -0,0 +1,203 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Broker carries hub messages between server instances. Publish hands a
+// message to every instance, this one included, and the deliver callback
+// given to Start receives messages as they arrive. LocalBroker suits a
+// single instance; PostgresBroker relays through LISTEN/NOTIFY so replicas
+// behind a load balancer all reach their own subscribers.
+type Broker interface {
+	Start(deliver func(topics []string, data []byte))
+	Publish(topics []string, data []byte)
+	Close() error
+}
+
+// LocalBroker delivers messages within this process only.
+type LocalBroker struct {
+	deliver func(topics []string, data []byte)
+}
+
+func NewLocalBroker() *LocalBroker {
+	return &LocalBroker{}
+}
+
+func (b *LocalBroker) Start(deliver func(topics []string, data []byte)) {
+	b.deliver = deliver
+}
+
+func (b *LocalBroker) Publish(topics []string, data []byte) {
+	if b.deliver != nil {
+		b.deliver(topics, data)
+	}
+}
+
+func (b *LocalBroker) Close() error {
+	return nil
+}
+
+// BrokerChannel is the Postgres notification channel hub messages travel on.
+const BrokerChannel = "vote_events"
+
+// NOTIFY payloads are capped at 8000 bytes; larger messages only reach
+// this instance's subscribers.
+const maxNotifyPayload = 7900
+
+type brokerEnvelope struct {
+	Origin string          `json:"origin"`
+	Topics []string        `json:"topics"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// PostgresBroker relays messages through Postgres LISTEN/NOTIFY. Messages
+// are delivered locally straight away and notified from a background
+// goroutine, so publishing never waits on the database; each instance
+// tags its notifications and ignores its own when they come back.
+//
+// LISTEN needs a session-level connection, so databaseURL must not point
+// at a transaction-pooling proxy.
+type PostgresBroker struct {
+	DB       *sql.DB
+	listener *pq.Listener
+	origin   string
+	deliver  func(topics []string, data []byte)
+	outbox   chan []byte
+	done     chan struct{}
+	dropped  atomic.Uint64
+}
+
+// NewPostgresBroker listens on BrokerChannel over its own connection to
+// databaseURL and sends notifications through db.
+func NewPostgresBroker(db *sql.DB, databaseURL string) (*PostgresBroker, error) {
+	listener := pq.NewListener(databaseURL, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventDisconnected:
+			log.Println("Event broker disconnected:", err)
+		case pq.ListenerEventReconnected:
+			log.Println("Event broker reconnected; messages sent while disconnected were missed")
+		case pq.ListenerEventConnectionAttemptFailed:
+			log.Println("Event broker connection attempt failed:", err)
+		}
+	})
+	if err := listener.Listen(BrokerChannel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	origin := make([]byte, 8)
+	if _, err := rand.Read(origin); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	return &PostgresBroker{
+		DB:       db,
+		listener: listener,
+		origin:   hex.EncodeToString(origin),
+		outbox:   make(chan []byte, broadcastQueue),
+		done:     make(chan struct{}),
+	}, nil
+}
+
+func (b *PostgresBroker) Start(deliver func(topics []string, data []byte)) {
+	b.deliver = deliver
+	go b.listen()
+	go b.notify()
+}
+
+func (b *PostgresBroker) Publish(topics []string, data []byte) {
+	if b.deliver != nil {
+		b.deliver(topics, data)
+	}
+
+	payload, err := json.Marshal(brokerEnvelope{Origin: b.origin, Topics: topics, Data: data})
+	if err != nil {
+		log.Println("Failed to encode broker message:", err)
+		return
+	}
+	if len(payload) > maxNotifyPayload {
+		log.Printf("Broker message of %d bytes is too large to relay to other instances", len(payload))
+		return
+	}
+
+	select {
+	case b.outbox <- payload:
+	default:
+		b.dropped.Add(1)
+	}
+}
+
+// Dropped counts messages that never reached other instances because the
+// outbox was full.
+func (b *PostgresBroker) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+func (b *PostgresBroker) Close() error {
+	select {
+	case <-b.done:
+		return nil
+	default:
+		close(b.done)
+	}
+	return b.listener.Close()
+}
+
+func (b *PostgresBroker) notify() {
+	for {
+		select {
+		case payload := <-b.outbox:
+			if _, err := b.DB.Exec(`SELECT pg_notify($1, $2)`, BrokerChannel, string(payload)); err != nil {
+				log.Println("Failed to notify other instances:", err)
+			}
+		case <-b.done:
+			return
+		}
+	}
+}
+
+func (b *PostgresBroker) listen() {
+	// An idle listener connection can die without notice; pinging it
+	// makes pq notice and reconnect.
+	ticker := time.NewTicker(90 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case n, ok := <-b.listener.Notify:
+			if !ok {
+				return
+			}
+			// pq sends nil after reconnecting.
+			if n == nil {
+				continue
+			}
+
+			var envelope brokerEnvelope
+			if err := json.Unmarshal([]byte(n.Extra), &envelope); err != nil {
+				log.Println("Ignoring malformed broker message:", err)
+				continue
+			}
+			if envelope.Origin == b.origin {
+				continue
+			}
+			b.deliver(envelope.Topics, envelope.Data)
+
+		case <-ticker.C:
+			go b.listener.Ping()
+
+		case <-b.done:
+			return
+		}
+	}
+}
@@ -1,70 +1,237 @@
 package services
 
 import (
+	"container/list"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-type CacheItem struct {
-	Value      interface{}
-	Expiration time.Time
+// Cache holds encoded responses for hot read paths. MemoryCache keeps them
+// in process; when several instances run behind a load balancer, a shared
+// implementation can be swapped in so an invalidation on one instance is
+// seen by all of them. Values are opaque bytes so any backend can store
+// them.
+//
+// A key's generation changes whenever a Delete or DeletePrefix covers it.
+// A reader that misses notes it before loading from the database and
+// stores the result with SetIfGeneration, which discards it if the key was
+// invalidated in between, so a stale result is never cached for a full
+// TTL. Invalidating one group of keys does not hold back fills of another.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	SetIfGeneration(key string, value []byte, ttl time.Duration, generation uint64) bool
+	Generation(key string) uint64
+	Delete(key string)
+	DeletePrefix(prefix string)
+	Stats() CacheStats
 }
 
-type Cache struct {
-	items map[string]CacheItem
-	mutex sync.RWMutex
+// CacheStats reports how well a cache is serving its readers.
+type CacheStats struct {
+	Hits      uint64  `json:"hits"`
+	Misses    uint64  `json:"misses"`
+	Evictions uint64  `json:"evictions"`
+	HitRatio  float64 `json:"hit_ratio"`
+	Entries   int     `json:"entries"`
+	Capacity  int     `json:"capacity"`
 }
 
-func NewCache() *Cache {
-	c := &Cache{
-		items: make(map[string]CacheItem),
+// Cache keys and key prefixes for the cached read paths, grouped so that
+// a change can drop everything derived from it.
+const (
+	CachePrefixPolicies   = "policies:"
+	CachePrefixCategories = "categories:"
+	CacheKeyStats         = "stats"
+	CacheKeyAnalytics     = "analytics"
+)
+
+// InvalidatePolicies drops every cached entry derived from policies: the
+// public lists, admin stats and analytics. Votes do not call it; lists
+// carry live counts merged in per request, and stats and analytics are
+// allowed to lag votes by their TTL.
+func InvalidatePolicies(c Cache) {
+	if c == nil {
+		return
+	}
+	c.DeletePrefix(CachePrefixPolicies)
+	c.Delete(CacheKeyStats)
+	c.Delete(CacheKeyAnalytics)
+}
+
+type cacheEntry struct {
+	key        string
+	value      []byte
+	expiration time.Time
+}
+
+// MemoryCache is an in-process Cache bounded to a number of entries,
+// evicting the least recently used one when full. Generations are counted
+// per deleted key or prefix; the callers only ever delete a few fixed
+// ones, so that map stays small.
+type MemoryCache struct {
+	capacity    int
+	items       map[string]*list.Element
+	order       *list.List
+	generations map[string]uint64
+	mutex       sync.Mutex
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+func NewMemoryCache(capacity int) *MemoryCache {
+	c := &MemoryCache{
+		capacity:    capacity,
+		items:       make(map[string]*list.Element),
+		order:       list.New(),
+		generations: make(map[string]uint64),
 	}
 	go c.cleanupExpired()
 	return c
 }
 
-func (c *Cache) Set(key string, value interface{}, duration time.Duration) {
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	c.items[key] = CacheItem{
-		Value:      value,
-		Expiration: time.Now().Add(duration),
+	el, exists := c.items[key]
+	if !exists {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	entry := el.Value.(*cacheEntry)
+	if time.Now().After(entry.expiration) {
+		c.remove(el)
+		c.misses.Add(1)
+		return nil, false
 	}
+
+	c.order.MoveToFront(el)
+	c.hits.Add(1)
+	return entry.value, true
 }
 
-func (c *Cache) Get(key string) (interface{}, bool) {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
+func (c *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 
-	item, exists := c.items[key]
-	if !exists {
-		return nil, false
+	c.set(key, value, ttl)
+}
+
+func (c *MemoryCache) SetIfGeneration(key string, value []byte, ttl time.Duration, generation uint64) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.generation(key) != generation {
+		return false
 	}
+	c.set(key, value, ttl)
+	return true
+}
 
-	if time.Now().After(item.Expiration) {
-		return nil, false
+func (c *MemoryCache) Generation(key string) uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.generation(key)
+}
+
+// generation sums the counters of every deleted key or prefix covering
+// key, so it grows whenever any of them is invalidated. It must be called
+// with the mutex held.
+func (c *MemoryCache) generation(key string) uint64 {
+	var generation uint64
+	for prefix, count := range c.generations {
+		if strings.HasPrefix(key, prefix) {
+			generation += count
+		}
+	}
+	return generation
+}
+
+// set must be called with the mutex held.
+func (c *MemoryCache) set(key string, value []byte, ttl time.Duration) {
+	if el, exists := c.items[key]; exists {
+		entry := el.Value.(*cacheEntry)
+		entry.value = value
+		entry.expiration = time.Now().Add(ttl)
+		c.order.MoveToFront(el)
+		return
 	}
 
-	return item.Value, true
+	c.items[key] = c.order.PushFront(&cacheEntry{
+		key:        key,
+		value:      value,
+		expiration: time.Now().Add(ttl),
+	})
+
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+		c.evictions.Add(1)
+	}
 }
 
-func (c *Cache) Delete(key string) {
+func (c *MemoryCache) Delete(key string) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	delete(c.items, key)
+
+	c.generations[key]++
+	if el, exists := c.items[key]; exists {
+		c.remove(el)
+	}
+}
+
+func (c *MemoryCache) DeletePrefix(prefix string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generations[prefix]++
+	for key, el := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.remove(el)
+		}
+	}
+}
+
+func (c *MemoryCache) Stats() CacheStats {
+	c.mutex.Lock()
+	entries := c.order.Len()
+	c.mutex.Unlock()
+
+	stats := CacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Entries:   entries,
+		Capacity:  c.capacity,
+	}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(lookups)
+	}
+	return stats
+}
+
+// remove must be called with the mutex held.
+func (c *MemoryCache) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*cacheEntry).key)
 }
 
-func (c *Cache) cleanupExpired() {
+func (c *MemoryCache) cleanupExpired() {
 	ticker := time.NewTicker(5 * time.Minute)
 	defer ticker.Stop()
 
 	for range ticker.C {
 		c.mutex.Lock()
 		now := time.Now()
-		for key, item := range c.items {
-			if now.After(item.Expiration) {
-				delete(c.items, key)
+		for _, el := range c.items {
+			if now.After(el.Value.(*cacheEntry).expiration) {
+				c.remove(el)
 			}
 		}
 		c.mutex.Unlock()
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"database/sql"
+	"log"
+	"time"
+	"vote/internal/models"
+	"vote/internal/utils"
+)
+
+// VoteCounterReconciler periodically checks the upvotes and downvotes
+// counters on policies against the votes table and repairs any drift, such
+// as from rows changed while the counting trigger was disabled.
+type VoteCounterReconciler struct {
+	DB          *sql.DB
+	AuditLogger *utils.AuditLogger
+	Interval    time.Duration
+}
+
+func NewVoteCounterReconciler(db *sql.DB, auditLogger *utils.AuditLogger, interval time.Duration) *VoteCounterReconciler {
+	return &VoteCounterReconciler{
+		DB:          db,
+		AuditLogger: auditLogger,
+		Interval:    interval,
+	}
+}
+
+func (r *VoteCounterReconciler) Run() {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	r.reconcile()
+	for range ticker.C {
+		r.reconcile()
+	}
+}
+
+func (r *VoteCounterReconciler) reconcile() {
+	repairs, err := ReconcileVoteCounters(r.DB)
+	if err != nil {
+		log.Printf("Failed to reconcile vote counters: %v", err)
+	}
+
+	for _, repair := range repairs {
+		log.Printf("Repaired vote counters for policy %s: %d/%d -> %d/%d", repair.PolicyID,
+			repair.StoredUpvotes, repair.StoredDownvotes, repair.Upvotes, repair.Downvotes)
+
+		if r.AuditLogger != nil {
+			r.AuditLogger.LogSystem("repair_vote_counters", "policy", repair.PolicyID, repair)
+		}
+	}
+}
+
+// ReconcileVoteCounters finds policies whose counters disagree with their
+// votes and recounts each under a row lock. Vote changes update the counter
+// while holding the same lock, so a recount never races a vote in flight;
+// a policy that is consistent by the time it is locked is left alone.
+func ReconcileVoteCounters(db *sql.DB) ([]models.VoteCounterRepair, error) {
+	rows, err := db.Query(`
+		SELECT p.id
+		FROM policies p
+		LEFT JOIN (
+			SELECT
+				policy_id,
+				COUNT(*) FILTER (WHERE vote_type = 'upvote') AS upvotes,
+				COUNT(*) FILTER (WHERE vote_type = 'downvote') AS downvotes
+			FROM votes
+			GROUP BY policy_id
+		) v ON v.policy_id = p.id
+		WHERE p.upvotes <> COALESCE(v.upvotes, 0) OR p.downvotes <> COALESCE(v.downvotes, 0)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	drifted := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			continue
+		}
+		drifted = append(drifted, id)
+	}
+	rows.Close()
+
+	repairs := []models.VoteCounterRepair{}
+	for _, id := range drifted {
+		repair, err := repairVoteCounters(db, id)
+		if err != nil {
+			return repairs, err
+		}
+		if repair != nil {
+			repairs = append(repairs, *repair)
+		}
+	}
+
+	return repairs, nil
+}
+
+func repairVoteCounters(db *sql.DB, policyID string) (*models.VoteCounterRepair, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	repair := models.VoteCounterRepair{PolicyID: policyID}
+	err = tx.QueryRow(`
+		SELECT upvotes, downvotes FROM policies WHERE id = $1 FOR UPDATE
+	`, policyID).Scan(&repair.StoredUpvotes, &repair.StoredDownvotes)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(`
+		SELECT
+			COUNT(*) FILTER (WHERE vote_type = 'upvote'),
+			COUNT(*) FILTER (WHERE vote_type = 'downvote')
+		FROM votes
+		WHERE policy_id = $1
+	`, policyID).Scan(&repair.Upvotes, &repair.Downvotes)
+	if err != nil {
+		return nil, err
+	}
+
+	if repair.Upvotes == repair.StoredUpvotes && repair.Downvotes == repair.StoredDownvotes {
+		return nil, nil
+	}
+
+	_, err = tx.Exec(`
+		UPDATE policies SET upvotes = $1, downvotes = $2 WHERE id = $3
+	`, repair.Upvotes, repair.Downvotes, policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &repair, nil
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"vote/internal/models"
+	"vote/internal/utils"
+)
+
+var (
+	ErrPolicyNotFound = errors.New("policy not found")
+	ErrNoDecisionRule = errors.New("no decision rule applies to this policy")
+)
+
+// outcomeStatus maps a decision outcome to the policy status it recommends.
+var outcomeStatus = map[string]string{
+	"passed":    "approved",
+	"failed":    "rejected",
+	"no_quorum": "uncertain",
+}
+
+// DecisionEngine evaluates quorum and threshold rules against a policy's
+// votes and records the result, applying it when the rule is automatic.
+type DecisionEngine struct {
+	DB          *sql.DB
+	Events      *EventBus
+	AuditLogger *utils.AuditLogger
+	Cache       Cache
+	SecretVotes *SecretVoteBatcher
+}
+
+func NewDecisionEngine(db *sql.DB, events *EventBus, auditLogger *utils.AuditLogger, cache Cache, secretVotes *SecretVoteBatcher) *DecisionEngine {
+	return &DecisionEngine{
+		DB:          db,
+		Events:      events,
+		AuditLogger: auditLogger,
+		Cache:       cache,
+		SecretVotes: secretVotes,
+	}
+}
+
+// Decide computes the outcome of a tally under a rule. Turnout is checked
+// first so a policy without quorum is never reported as failed.
+func Decide(rule models.DecisionRule, d *models.PolicyDecision) {
+	total := d.Upvotes + d.Downvotes
+	d.NetVotes = d.Upvotes - d.Downvotes
+	d.ApprovalRatio = 0
+	if total > 0 {
+		d.ApprovalRatio = float64(d.Upvotes) / float64(total)
+	}
+	d.TurnoutPercent = 0
+	if d.ActiveStudents > 0 {
+		d.TurnoutPercent = float64(d.Voters) / float64(d.ActiveStudents) * 100
+	}
+
+	switch {
+	case rule.MinTurnoutPercent != nil && d.TurnoutPercent < *rule.MinTurnoutPercent:
+		d.Outcome = "no_quorum"
+	case total == 0:
+		d.Outcome = "no_quorum"
+	case rule.MinApprovalRatio != nil && d.ApprovalRatio < *rule.MinApprovalRatio:
+		d.Outcome = "failed"
+	case rule.MinNetVotes != nil && d.NetVotes < *rule.MinNetVotes:
+		d.Outcome = "failed"
+	case rule.MinApprovalRatio == nil && rule.MinNetVotes == nil && d.NetVotes <= 0:
+		d.Outcome = "failed"
+	default:
+		d.Outcome = "passed"
+	}
+
+	d.RecommendedStatus = outcomeStatus[d.Outcome]
+}
+
+// RuleFor returns the most specific rule for a policy: its own, then its
+// category's, then the global rule.
+func (e *DecisionEngine) RuleFor(policyID string, categoryID *string) (*models.DecisionRule, error) {
+	var rule models.DecisionRule
+	err := e.DB.QueryRow(`
+		SELECT id, category_id, policy_id, min_turnout_percent, min_approval_ratio,
+			min_net_votes, mode, created_at, updated_at
+		FROM decision_rules
+		WHERE policy_id = $1
+			OR category_id = $2
+			OR (policy_id IS NULL AND category_id IS NULL)
+		ORDER BY (policy_id IS NOT NULL) DESC, (category_id IS NOT NULL) DESC
+		LIMIT 1
+	`, policyID, categoryID).Scan(
+		&rule.ID, &rule.CategoryID, &rule.PolicyID, &rule.MinTurnoutPercent,
+		&rule.MinApprovalRatio, &rule.MinNetVotes, &rule.Mode, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoDecisionRule
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// Evaluate records a decision for the policy. The recommended status is
+// applied when the rule is automatic or when forceApply is set. A nil actorID
+// marks the decision as taken by the scheduler.
+func (e *DecisionEngine) Evaluate(policyID string, actorID *string, forceApply bool) (*models.PolicyDecision, error) {
+	var categoryID *string
+	err := e.DB.QueryRow(`SELECT category_id FROM policies WHERE id = $1`, policyID).Scan(&categoryID)
+	if err == sql.ErrNoRows {
+		return nil, ErrPolicyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := e.RuleFor(policyID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &models.PolicyDecision{
+		PolicyID:  policyID,
+		RuleID:    &rule.ID,
+		DecidedBy: actorID,
+	}
+
+	tx, err := e.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Queued secret ballot votes are moved in the same transaction, so the
+	// decision counts them whichever instance they were cast on.
+	secretVotes, err := e.SecretVotes.FlushPolicy(tx, policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Secret ballot votes carry no user, so their voters come from
+	// vote_participation instead.
+	err = tx.QueryRow(`
+		SELECT
+			p.upvotes, p.downvotes,
+			(SELECT COUNT(DISTINCT user_id) FROM votes WHERE policy_id = $1)
+				+ (SELECT COUNT(DISTINCT user_id) FROM vote_participation WHERE policy_id = $1) as voters
+		FROM policies p
+		WHERE p.id = $1
+	`, policyID).Scan(&d.Upvotes, &d.Downvotes, &d.Voters)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(`
+		SELECT COUNT(*) FROM users WHERE role = 'student' AND is_active = true
+	`).Scan(&d.ActiveStudents)
+	if err != nil {
+		return nil, err
+	}
+
+	Decide(*rule, d)
+	d.Applied = rule.Mode == "automatic" || forceApply
+	d.Rule, _ = json.Marshal(rule)
+
+	err = tx.QueryRow(`
+		INSERT INTO policy_decisions (
+			policy_id, rule_id, rule_snapshot, outcome, recommended_status, applied,
+			upvotes, downvotes, voters, active_students, turnout_percent,
+			approval_ratio, net_votes, decided_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id, created_at
+	`, d.PolicyID, d.RuleID, []byte(d.Rule), d.Outcome, d.RecommendedStatus, d.Applied,
+		d.Upvotes, d.Downvotes, d.Voters, d.ActiveStudents, d.TurnoutPercent,
+		d.ApprovalRatio, d.NetVotes, d.DecidedBy).Scan(&d.ID, &d.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	previousStatus := ""
+	if d.Applied {
+		comment := fmt.Sprintf("Decision rule outcome: %s", d.Outcome)
+		previousStatus, err = TransitionPolicy(tx, policyID, d.RecommendedStatus, actorID, &comment)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if secretVotes > 0 {
+		e.SecretVotes.Announce(policyID)
+	}
+
+	if e.AuditLogger != nil {
+		details := map[string]interface{}{
+			"decision_id":        d.ID,
+			"rule_id":            rule.ID,
+			"outcome":            d.Outcome,
+			"recommended_status": d.RecommendedStatus,
+			"applied":            d.Applied,
+			"upvotes":            d.Upvotes,
+			"downvotes":          d.Downvotes,
+			"turnout_percent":    d.TurnoutPercent,
+			"approval_ratio":     d.ApprovalRatio,
+			"net_votes":          d.NetVotes,
+		}
+		if actorID != nil {
+			e.AuditLogger.Log(*actorID, "evaluate_policy", "policy", policyID, details)
+		} else {
+			e.AuditLogger.LogSystem("evaluate_policy", "policy", policyID, details)
+		}
+	}
+
+	if d.Applied {
+		InvalidatePolicies(e.Cache)
+		e.Events.PublishStatusChange(policyID, previousStatus, d.RecommendedStatus)
+	}
+
+	return d, nil
+}
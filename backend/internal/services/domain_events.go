@@ -0,0 +1,102 @@
+package services
+
+import "sync"
+
+// Domain events describe what changed, not who should hear about it.
+// Handlers and background jobs publish them after their transaction
+// commits; subscribers such as the WebSocket hub decide what to do with
+// them.
+const (
+	EventPolicyCreated   = "policy_created"
+	EventPolicyApproved  = "policy_approved"
+	EventPolicyPublished = "policy_published"
+	EventStatusChanged   = "status_changed"
+	EventPolicyDeleted   = "policy_deleted"
+	EventCommentAdded    = "comment_added"
+	EventCategoryChanged = "category_changed"
+)
+
+// DomainEvent is a change to a policy or its discussion. CategoryID and
+// AuthorID may be left empty when the policy still exists; subscribers
+// look them up. Events about a deleted policy must carry them.
+type DomainEvent struct {
+	Type               string
+	PolicyID           string
+	CategoryID         string
+	AuthorID           string
+	Status             string
+	PreviousStatus     string
+	PreviousCategoryID string
+	CommentID          string
+}
+
+// EventBus hands domain events to its subscribers synchronously, in the
+// order they subscribed. A nil bus drops events, so code that runs without
+// one needs no checks.
+type EventBus struct {
+	subscribers []func(DomainEvent)
+	mutex       sync.RWMutex
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+func (b *EventBus) Subscribe(handler func(DomainEvent)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.subscribers = append(b.subscribers, handler)
+}
+
+func (b *EventBus) Publish(event DomainEvent) {
+	if b == nil {
+		return
+	}
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	for _, handler := range b.subscribers {
+		handler(event)
+	}
+}
+
+// PublishStatusChange announces a lifecycle transition. It also announces
+// that the policy became visible whenever it moves from a status students
+// cannot see to one they can, whichever status that is, and that it was
+// approved when it leaves review for the approved status.
+func (b *EventBus) PublishStatusChange(policyID, previousStatus, status string) {
+	b.Publish(DomainEvent{
+		Type:           EventStatusChanged,
+		PolicyID:       policyID,
+		Status:         status,
+		PreviousStatus: previousStatus,
+	})
+
+	if IsPublicStatus(status) && !IsPublicStatus(previousStatus) {
+		b.Publish(DomainEvent{
+			Type:           EventPolicyPublished,
+			PolicyID:       policyID,
+			Status:         status,
+			PreviousStatus: previousStatus,
+		})
+	}
+
+	if status == "approved" && previousStatus == "pending" {
+		b.Publish(DomainEvent{
+			Type:           EventPolicyApproved,
+			PolicyID:       policyID,
+			Status:         status,
+			PreviousStatus: previousStatus,
+		})
+	}
+}
+
+// IsPublicStatus reports whether students can see a policy in status.
+func IsPublicStatus(status string) bool {
+	for _, s := range PublicStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"database/sql"
+	"vote/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// DuplicateThreshold is the similarity above which a policy is reported as a
+// likely duplicate of a new submission.
+const DuplicateThreshold = 0.3
+
+// FindSimilarPolicies scores policies in the given statuses against a title
+// and description using trigram similarity, weighting the title more
+// heavily since students tend to phrase the same idea in a similar headline.
+// excludeID leaves out the policy being compared, if any, and submittedBy
+// limits the search to one author's policies.
+func FindSimilarPolicies(db *sql.DB, title, description string, statuses []string, excludeID, submittedBy *string, limit int) ([]models.SimilarPolicy, error) {
+	rows, err := db.Query(`
+		SELECT s.id, s.title, s.status, s.score, s.upvotes, s.downvotes
+		FROM (
+			SELECT p.id, p.title, p.status, p.upvotes, p.downvotes,
+				0.6 * similarity(p.title, $1) + 0.4 * similarity(p.description, $2) as score
+			FROM policies p
+			WHERE (p.title % $1 OR p.description % $2)
+				AND p.status = ANY($3)
+				AND p.id IS DISTINCT FROM $4
+				AND ($7::uuid IS NULL OR p.submitted_by = $7)
+		) s
+		WHERE s.score >= $5
+		ORDER BY s.score DESC
+		LIMIT $6
+	`, title, description, pq.Array(statuses), excludeID, DuplicateThreshold, limit, submittedBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	similar := []models.SimilarPolicy{}
+	for rows.Next() {
+		var s models.SimilarPolicy
+		if err := rows.Scan(&s.ID, &s.Title, &s.Status, &s.Similarity, &s.Upvotes, &s.Downvotes); err != nil {
+			return nil, err
+		}
+		similar = append(similar, s)
+	}
+
+	return similar, rows.Err()
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"time"
+)
+
+// keepAlivePeriod spaces the comments written to an idle event stream,
+// which keep proxies from timing it out and reveal a departed client.
+const keepAlivePeriod = 25 * time.Second
+
+// NewEventClient prepares a Server-Sent Events subscriber for StreamEvents.
+// Unlike a socket it cannot change its subscriptions later, so it follows
+// topics from the start; lastEventID, when set, resumes after that event.
+// The stream ends once expires, the expiry of the client's token, passes.
+func (h *WebSocketHub) NewEventClient(userID, role string, expires time.Time, topics []string, lastEventID string) (*WSClient, error) {
+	client := &WSClient{
+		UserID:     userID,
+		Role:       role,
+		send:       make(chan wsFrame, sendQueueSize),
+		done:       make(chan struct{}),
+		topics:     map[string]bool{},
+		resumeFrom: lastEventID,
+		expires:    expires,
+		db:         h.DB,
+	}
+
+	for _, topic := range topics {
+		if _, err := client.apply(WSCommand{Action: "subscribe", Topic: topic}); err != nil {
+			return nil, fmt.Errorf("%s: %w", topic, err)
+		}
+	}
+
+	return client, nil
+}
+
+// StreamEvents writes the client's messages to w in event stream format
+// until the client goes away or the hub drops it. Each broadcast carries
+// its event ID so a reconnecting client can send it back as Last-Event-ID.
+func (h *WebSocketHub) StreamEvents(w *bufio.Writer, client *WSClient) {
+	select {
+	case h.register <- client:
+	case <-h.quit:
+		return
+	}
+	defer func() {
+		select {
+		case h.unregister <- client:
+		case <-h.done:
+		}
+	}()
+
+	fmt.Fprint(w, "retry: 3000\n\n")
+	if err := w.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(keepAlivePeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case frame := <-client.send:
+			if frame.id != "" {
+				fmt.Fprintf(w, "id: %s\n", frame.id)
+			}
+			fmt.Fprintf(w, "data: %s\n\n", frame.data)
+
+		case <-ticker.C:
+			if client.expired() {
+				return
+			}
+			fmt.Fprint(w, ": ping\n\n")
+
+		case <-client.done:
+			return
+		}
+
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
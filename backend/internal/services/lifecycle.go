@@ -0,0 +1,131 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// statusTransitions declares the policy lifecycle. A submission is reviewed
+// into one of the voting statuses, which an admin may revise until work
+// starts; once in progress it can only finish, pause or be abandoned.
+var statusTransitions = map[string][]string{
+	"pending":          {"approved", "rejected", "uncertain"},
+	"approved":         {"rejected", "uncertain", "closed", "in_progress"},
+	"rejected":         {"approved", "uncertain", "closed", "in_progress"},
+	"uncertain":        {"approved", "rejected", "closed", "in_progress"},
+	"closed":           {"approved", "rejected", "uncertain", "in_progress"},
+	"in_progress":      {"completed", "on_hold", "cannot_implement"},
+	"on_hold":          {"in_progress", "cannot_implement"},
+	"completed":        {},
+	"cannot_implement": {},
+	"withdrawn":        {},
+	"merged":           {},
+}
+
+// authorTransitions are the changes a policy's author may make themselves.
+// They are kept apart from statusTransitions so reviewers cannot withdraw a
+// submission on the author's behalf.
+var authorTransitions = map[string][]string{
+	"pending": {"withdrawn"},
+}
+
+// mergeTransitions are the statuses a policy can be merged from. Once work
+// has started on a policy it is no longer folded into another.
+var mergeTransitions = map[string][]string{
+	"pending":   {"merged"},
+	"approved":  {"merged"},
+	"rejected":  {"merged"},
+	"uncertain": {"merged"},
+	"closed":    {"merged"},
+}
+
+// PublicStatuses are the statuses in which students can see and vote on a
+// policy.
+var PublicStatuses = []string{
+	"approved", "uncertain", "rejected", "closed",
+	"in_progress", "completed", "on_hold", "cannot_implement",
+}
+
+// TransitionError reports a status change the lifecycle does not allow.
+type TransitionError struct {
+	From string
+	To   string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot change status from %s to %s", e.From, e.To)
+}
+
+func IsValidStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok
+}
+
+// AllowedTransitions lists the statuses a policy can move to from status.
+func AllowedTransitions(status string) []string {
+	if next, ok := statusTransitions[status]; ok {
+		return next
+	}
+	return []string{}
+}
+
+func CanTransition(from, to string) bool {
+	return allowed(statusTransitions, from, to)
+}
+
+func allowed(transitions map[string][]string, from, to string) bool {
+	for _, status := range transitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionPolicy moves a policy to a new status inside tx and records the
+// change in policy_status_history. It returns the previous status; moving to
+// the current status is a no-op. A nil actorID marks a system change.
+func TransitionPolicy(tx *sql.Tx, policyID, to string, actorID, comment *string) (string, error) {
+	return transition(tx, statusTransitions, policyID, to, actorID, comment)
+}
+
+// WithdrawPolicy lets an author retract their own submission while it is
+// still pending. Ownership is the caller's to check.
+func WithdrawPolicy(tx *sql.Tx, policyID, authorID string) (string, error) {
+	return transition(tx, authorTransitions, policyID, "withdrawn", &authorID, nil)
+}
+
+func transition(tx *sql.Tx, transitions map[string][]string, policyID, to string, actorID, comment *string) (string, error) {
+	var from string
+	err := tx.QueryRow(`SELECT status FROM policies WHERE id = $1 FOR UPDATE`, policyID).Scan(&from)
+	if err == sql.ErrNoRows {
+		return "", ErrPolicyNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if from == to {
+		return from, nil
+	}
+
+	if !allowed(transitions, from, to) {
+		return from, &TransitionError{From: from, To: to}
+	}
+
+	if _, err := tx.Exec(`UPDATE policies SET status = $1 WHERE id = $2`, to, policyID); err != nil {
+		return from, err
+	}
+
+	return from, RecordStatusChange(tx, policyID, &from, to, actorID, comment)
+}
+
+// RecordStatusChange appends an entry to a policy's status timeline. from is
+// nil for the status a policy starts with.
+func RecordStatusChange(tx *sql.Tx, policyID string, from *string, to string, actorID, comment *string) error {
+	_, err := tx.Exec(`
+		INSERT INTO policy_status_history (policy_id, from_status, to_status, comment, changed_by)
+		VALUES ($1, $2, $3, $4, $5)
+	`, policyID, from, to, comment, actorID)
+	return err
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"vote/internal/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrMergeSelf         = errors.New("a policy cannot be merged into itself")
+	ErrMergeTarget       = errors.New("policies cannot be merged into a withdrawn or merged policy")
+	ErrMergeSecretBallot = errors.New("secret ballot votes cannot be deduplicated, so those policies cannot be merged")
+	ErrMergeBallot       = errors.New("a policy that is an option on a draft or open ballot cannot be merged")
+	ErrSplitSource       = errors.New("a withdrawn or merged policy cannot be split")
+)
+
+// MergePolicy folds source into target inside tx: source's votes move to
+// target, except where the same voter already voted on target, and its
+// comment threads and revisions move with them. Moved revisions are marked
+// merged_from source so they are never restored onto target. Source is kept
+// with status merged and a pointer to target so old links and its vote
+// history still resolve.
+func MergePolicy(tx *sql.Tx, sourceID, targetID, actorID string) (*models.MergeResult, error) {
+	if sourceID == targetID {
+		return nil, ErrMergeSelf
+	}
+
+	// Lock both rows in a fixed order so concurrent merges cannot deadlock.
+	rows, err := tx.Query(`
+		SELECT id, title, status, secret_ballot FROM policies
+		WHERE id = ANY($1)
+		ORDER BY id
+		FOR UPDATE
+	`, pq.Array([]string{sourceID, targetID}))
+	if err != nil {
+		return nil, err
+	}
+
+	var targetTitle, targetStatus string
+	secret := false
+	found := 0
+	for rows.Next() {
+		var id, title, status string
+		var secretBallot bool
+		if err := rows.Scan(&id, &title, &status, &secretBallot); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if id == targetID {
+			targetTitle, targetStatus = title, status
+		}
+		secret = secret || secretBallot
+		found++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if found < 2 {
+		return nil, ErrPolicyNotFound
+	}
+	if targetStatus == "withdrawn" || targetStatus == "merged" {
+		return nil, ErrMergeTarget
+	}
+	if secret {
+		return nil, ErrMergeSecretBallot
+	}
+
+	// Ballot options point at the source, and votes already cast on a
+	// ballot cannot be moved to another option, so refuse rather than leave
+	// a live ballot offering a merged policy.
+	var onBallot bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM ballot_options o
+			JOIN ballots b ON b.id = o.ballot_id
+			WHERE o.policy_id = $1 AND b.status <> 'closed'
+		)
+	`, sourceID).Scan(&onBallot)
+	if err != nil {
+		return nil, err
+	}
+	if onBallot {
+		return nil, ErrMergeBallot
+	}
+
+	comment := fmt.Sprintf("Merged into \"%s\"", targetTitle)
+	previousStatus, err := transition(tx, mergeTransitions, sourceID, "merged", &actorID, &comment)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.MergeResult{
+		SourceID:       sourceID,
+		TargetID:       targetID,
+		PreviousStatus: previousStatus,
+	}
+
+	if _, err := tx.Exec(`UPDATE policies SET merged_into = $2 WHERE id = $1`, sourceID, targetID); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO vote_history (policy_id, user_id, action, previous_type, device_fingerprint)
+		SELECT policy_id, user_id, 'merge_out', vote_type, device_fingerprint
+		FROM votes WHERE policy_id = $1
+	`, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A voter who voted on both policies keeps their vote on the target.
+	dropped, err := tx.Exec(`
+		DELETE FROM votes s
+		WHERE s.policy_id = $1 AND EXISTS (
+			SELECT 1 FROM votes t
+			WHERE t.policy_id = $2 AND (
+				(s.user_id IS NOT NULL AND t.user_id = s.user_id)
+				OR (s.claims_device AND t.claims_device AND t.device_fingerprint = s.device_fingerprint)
+			)
+		)
+	`, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	droppedCount, _ := dropped.RowsAffected()
+	result.VotesDropped = int(droppedCount)
+
+	_, err = tx.Exec(`
+		INSERT INTO vote_history (policy_id, user_id, action, new_type, device_fingerprint)
+		SELECT $2, user_id, 'merge_in', vote_type, device_fingerprint
+		FROM votes WHERE policy_id = $1
+	`, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	moved, err := tx.Exec(`UPDATE votes SET policy_id = $2 WHERE policy_id = $1`, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	movedCount, _ := moved.RowsAffected()
+	result.VotesMoved = int(movedCount)
+
+	comments, err := tx.Query(`UPDATE comments SET policy_id = $2 WHERE policy_id = $1 RETURNING id`, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	result.CommentIDs = []string{}
+	for comments.Next() {
+		var id string
+		if err := comments.Scan(&id); err != nil {
+			comments.Close()
+			return nil, err
+		}
+		result.CommentIDs = append(result.CommentIDs, id)
+	}
+	comments.Close()
+	if err := comments.Err(); err != nil {
+		return nil, err
+	}
+	result.CommentsMoved = len(result.CommentIDs)
+
+	// Source revisions are numbered after the target's own, then the
+	// target's current text is snapshotted so it stays the latest revision.
+	revisions, err := tx.Exec(`
+		UPDATE policy_revisions
+		SET policy_id = $2,
+			origin_policy_id = COALESCE(origin_policy_id, $1),
+			merged_from = $1,
+			revision = revision + (SELECT COALESCE(MAX(revision), 0) FROM policy_revisions WHERE policy_id = $2)
+		WHERE policy_id = $1
+	`, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	revisionCount, _ := revisions.RowsAffected()
+	result.RevisionsMoved = int(revisionCount)
+
+	if revisionCount > 0 {
+		if _, err := RecordRevision(tx, targetID, actorID, "merge", nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// SplitPolicy creates a pending policy for each part, crediting the original
+// author and recording source as its provenance. The source itself is left
+// unchanged. It returns the new policy IDs in part order.
+func SplitPolicy(tx *sql.Tx, sourceID, actorID string, parts []models.SplitPart) ([]string, error) {
+	var title, status string
+	err := tx.QueryRow(`SELECT title, status FROM policies WHERE id = $1 FOR UPDATE`, sourceID).Scan(&title, &status)
+	if err == sql.ErrNoRows {
+		return nil, ErrPolicyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if status == "withdrawn" || status == "merged" {
+		return nil, ErrSplitSource
+	}
+
+	comment := fmt.Sprintf("Split from \"%s\"", title)
+	ids := []string{}
+	for _, part := range parts {
+		var id string
+		err := tx.QueryRow(`
+			INSERT INTO policies (title, description, submitted_by, status, category_id, split_from)
+			SELECT $2, $3, p.submitted_by, 'pending', COALESCE($4, p.category_id), p.id
+			FROM policies p
+			WHERE p.id = $1
+			RETURNING id
+		`, sourceID, part.Title, part.Description, part.CategoryID).Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := RecordRevision(tx, id, actorID, "split", nil); err != nil {
+			return nil, err
+		}
+
+		if err := RecordStatusChange(tx, id, nil, "pending", &actorID, &comment); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
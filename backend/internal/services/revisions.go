@@ -0,0 +1,20 @@
+package services
+
+import "database/sql"
+
+// RecordRevision snapshots the policy's current text as its next revision
+// and returns the revision number. Callers should hold a lock on the policy
+// row so concurrent edits cannot race for the same number.
+func RecordRevision(tx *sql.Tx, policyID, userID, action string, restoredFrom *int) (int, error) {
+	var revision int
+	err := tx.QueryRow(`
+		INSERT INTO policy_revisions (policy_id, revision, title, description, category_id, action, restored_from, edited_by)
+		SELECT p.id,
+			COALESCE((SELECT MAX(r.revision) FROM policy_revisions r WHERE r.policy_id = p.id), 0) + 1,
+			p.title, p.description, p.category_id, $2, $3, $4
+		FROM policies p
+		WHERE p.id = $1
+		RETURNING revision
+	`, policyID, action, restoredFrom, userID).Scan(&revision)
+	return revision, err
+}
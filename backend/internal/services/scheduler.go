@@ -0,0 +1,128 @@
+package services
+
+import (
+	"database/sql"
+	"log"
+	"time"
+	"vote/internal/utils"
+)
+
+// VotingScheduler closes policies whose voting window has ended, announces
+// the change to connected clients and evaluates any decision rule. Ballots
+// past their closing time are closed on the same tick.
+type VotingScheduler struct {
+	DB          *sql.DB
+	Events      *EventBus
+	AuditLogger *utils.AuditLogger
+	Decisions   *DecisionEngine
+	Cache       Cache
+	Interval    time.Duration
+}
+
+func NewVotingScheduler(db *sql.DB, events *EventBus, auditLogger *utils.AuditLogger, decisions *DecisionEngine, cache Cache, interval time.Duration) *VotingScheduler {
+	return &VotingScheduler{
+		DB:          db,
+		Events:      events,
+		AuditLogger: auditLogger,
+		Decisions:   decisions,
+		Cache:       cache,
+		Interval:    interval,
+	}
+}
+
+func (s *VotingScheduler) Run() {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.closeExpired()
+	for range ticker.C {
+		s.closeExpired()
+	}
+}
+
+func (s *VotingScheduler) closeExpired() {
+	s.closeExpiredBallots()
+
+	rows, err := s.DB.Query(`
+		WITH closed AS (
+			UPDATE policies p SET status = 'closed', voting_closed_at = NOW()
+			FROM policies old
+			WHERE p.id = old.id
+				AND p.voting_closes_at <= NOW()
+				AND (p.voting_closed_at IS NULL OR p.voting_closed_at < p.voting_closes_at)
+				AND p.status IN ('approved', 'uncertain', 'rejected')
+			RETURNING p.id, old.status
+		), history AS (
+			INSERT INTO policy_status_history (policy_id, from_status, to_status, comment)
+			SELECT id, status, 'closed', 'Voting window ended' FROM closed
+		)
+		SELECT id, status FROM closed
+	`)
+	if err != nil {
+		log.Printf("Failed to close expired voting windows: %v", err)
+		return
+	}
+	type closedPolicy struct {
+		id             string
+		previousStatus string
+	}
+
+	closed := []closedPolicy{}
+	for rows.Next() {
+		var p closedPolicy
+		if err := rows.Scan(&p.id, &p.previousStatus); err != nil {
+			continue
+		}
+		closed = append(closed, p)
+	}
+	rows.Close()
+
+	if len(closed) > 0 {
+		InvalidatePolicies(s.Cache)
+	}
+
+	for _, p := range closed {
+		if s.AuditLogger != nil {
+			s.AuditLogger.LogSystem("close_voting", "policy", p.id, map[string]interface{}{
+				"previous_status": p.previousStatus,
+			})
+		}
+
+		s.Events.PublishStatusChange(p.id, p.previousStatus, "closed")
+
+		if s.Decisions != nil {
+			_, err := s.Decisions.Evaluate(p.id, nil, false)
+			if err != nil && err != ErrNoDecisionRule {
+				log.Printf("Failed to evaluate decision rule for policy %s: %v", p.id, err)
+			}
+		}
+	}
+}
+
+func (s *VotingScheduler) closeExpiredBallots() {
+	rows, err := s.DB.Query(`
+		UPDATE ballots SET status = 'closed'
+		WHERE status = 'open' AND closes_at <= NOW()
+		RETURNING id
+	`)
+	if err != nil {
+		log.Printf("Failed to close expired ballots: %v", err)
+		return
+	}
+
+	closed := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			continue
+		}
+		closed = append(closed, id)
+	}
+	rows.Close()
+
+	if s.AuditLogger != nil {
+		for _, id := range closed {
+			s.AuditLogger.LogSystem("close_ballot", "ballot", id, nil)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"database/sql"
+	"log"
+	"time"
+)
+
+// ScoreRefresher periodically recomputes the policy_scores materialized
+// view behind the trending and controversial sorts. Between refreshes those
+// sorts use the previous scores; policies created since then score zero.
+type ScoreRefresher struct {
+	DB       *sql.DB
+	Interval time.Duration
+}
+
+func NewScoreRefresher(db *sql.DB, interval time.Duration) *ScoreRefresher {
+	return &ScoreRefresher{
+		DB:       db,
+		Interval: interval,
+	}
+}
+
+func (r *ScoreRefresher) Run() {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	r.refresh()
+	for range ticker.C {
+		r.refresh()
+	}
+}
+
+// refresh rebuilds the view without blocking readers, who keep seeing the
+// old scores until the new ones are ready.
+func (r *ScoreRefresher) refresh() {
+	if _, err := r.DB.Exec(`REFRESH MATERIALIZED VIEW CONCURRENTLY policy_scores`); err != nil {
+		log.Printf("Failed to refresh policy scores: %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"database/sql"
+	"log"
+	"math/rand/v2"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// SecretVoteBatcher moves secret ballot choices from secret_vote_queue into
+// votes in shuffled batches, each in a transaction of its own. A vote
+// written alongside its voter's participation record would share that
+// record's transaction and sit next to it on disk, and a tally pushed after
+// each vote would tell anyone watching when a person voted how they voted.
+//
+// The queue is a table, so choices survive a restart and every instance
+// sees all of them. A policy's choices are moved once MinBatch of them are
+// waiting, and the tally is announced only then. A smaller batch is only
+// written by FlushPolicy once voting has closed, so a lone vote never shows
+// up as a change in the tally while it could still be linked to a voter.
+type SecretVoteBatcher struct {
+	DB       *sql.DB
+	WSHub    *WebSocketHub
+	Interval time.Duration
+	MinBatch int
+}
+
+func NewSecretVoteBatcher(db *sql.DB, wsHub *WebSocketHub, interval time.Duration) *SecretVoteBatcher {
+	return &SecretVoteBatcher{
+		DB:       db,
+		WSHub:    wsHub,
+		Interval: interval,
+		MinBatch: 5,
+	}
+}
+
+func (b *SecretVoteBatcher) Run() {
+	ticker := time.NewTicker(b.Interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		b.flush()
+	}
+}
+
+// FlushPolicy moves the policy's queued choices into votes inside tx, so a
+// decision recorded in the same transaction counts them. While voting is
+// still open only a full batch is moved. It returns how many votes were
+// moved; the caller passes the policy to Announce once tx commits.
+func (b *SecretVoteBatcher) FlushPolicy(tx *sql.Tx, policyID string) (int, error) {
+	if b == nil {
+		return 0, nil
+	}
+
+	var open bool
+	err := tx.QueryRow(`
+		SELECT status IN ('approved', 'uncertain', 'rejected')
+			AND (voting_opens_at IS NULL OR voting_opens_at <= NOW())
+			AND (voting_closes_at IS NULL OR voting_closes_at > NOW())
+		FROM policies WHERE id = $1
+	`, policyID).Scan(&open)
+	if err != nil {
+		return 0, err
+	}
+
+	if open {
+		return moveSecretVotes(tx, policyID, b.MinBatch, true)
+	}
+	return moveSecretVotes(tx, policyID, 1, false)
+}
+
+// Announce pushes the policy's tally after queued votes were moved into it.
+func (b *SecretVoteBatcher) Announce(policyID string) {
+	if b.WSHub != nil {
+		var upvotes, downvotes int
+		b.DB.QueryRow(`SELECT upvotes, downvotes FROM policies WHERE id = $1`, policyID).Scan(&upvotes, &downvotes)
+		b.WSHub.BroadcastVoteUpdate(policyID, upvotes, downvotes)
+	}
+}
+
+func (b *SecretVoteBatcher) flush() {
+	rows, err := b.DB.Query(`
+		SELECT policy_id FROM secret_vote_queue
+		GROUP BY policy_id
+		HAVING COUNT(*) >= $1
+	`, b.MinBatch)
+	if err != nil {
+		log.Printf("Failed to find queued secret votes: %v", err)
+		return
+	}
+
+	var policyIDs []string
+	for rows.Next() {
+		var policyID string
+		if err := rows.Scan(&policyID); err == nil {
+			policyIDs = append(policyIDs, policyID)
+		}
+	}
+	rows.Close()
+
+	for _, policyID := range policyIDs {
+		if err := b.move(policyID); err != nil {
+			log.Printf("Failed to write secret votes for policy %s: %v", policyID, err)
+		}
+	}
+}
+
+func (b *SecretVoteBatcher) move(policyID string) error {
+	tx, err := b.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	moved, err := moveSecretVotes(tx, policyID, b.MinBatch, true)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if moved > 0 {
+		b.Announce(policyID)
+	}
+	return nil
+}
+
+// moveSecretVotes claims the policy's queued choices and, if there are at
+// least min of them, writes them to votes in random order without a user,
+// device or precise time, and deletes them from the queue. With skipLocked
+// set, choices another instance is already moving are left to it, so that
+// two instances never split a batch into pieces smaller than min.
+func moveSecretVotes(tx *sql.Tx, policyID string, min int, skipLocked bool) (int, error) {
+	query := `SELECT id, vote_type FROM secret_vote_queue WHERE policy_id = $1 FOR UPDATE`
+	if skipLocked {
+		query += ` SKIP LOCKED`
+	}
+
+	rows, err := tx.Query(query, policyID)
+	if err != nil {
+		return 0, err
+	}
+
+	var ids, voteTypes []string
+	for rows.Next() {
+		var id, voteType string
+		if err := rows.Scan(&id, &voteType); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+		voteTypes = append(voteTypes, voteType)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(ids) == 0 || len(ids) < min {
+		return 0, nil
+	}
+
+	rand.Shuffle(len(voteTypes), func(i, j int) {
+		voteTypes[i], voteTypes[j] = voteTypes[j], voteTypes[i]
+	})
+
+	// The timestamp is truncated to the day so it cannot be matched
+	// against the participation record.
+	for _, voteType := range voteTypes {
+		_, err := tx.Exec(`
+			INSERT INTO votes (policy_id, user_id, vote_type, claims_user, claims_device, created_at)
+			VALUES ($1, NULL, $2, false, false, date_trunc('day', NOW()))
+		`, policyID, voteType)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM secret_vote_queue WHERE id = ANY($1)`, pq.Array(ids)); err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
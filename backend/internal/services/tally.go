@@ -0,0 +1,285 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"vote/internal/models"
+)
+
+var ErrBallotNotFound = errors.New("ballot not found")
+
+// Tally counts ballots under the given method. Each ballot lists option IDs
+// in preference order; only the first entry counts for plurality and every
+// entry counts for approval.
+func Tally(method string, options []models.BallotOption, ballots [][]string) models.BallotResult {
+	result := models.BallotResult{
+		Method:       method,
+		TotalBallots: len(ballots),
+		Rounds:       []models.BallotRound{},
+		Winners:      []string{},
+	}
+
+	if method == "irv" {
+		tallyRunoff(&result, options, ballots)
+		return result
+	}
+
+	votes := map[string]int{}
+	for _, ballot := range ballots {
+		if len(ballot) == 0 {
+			continue
+		}
+		if method == "plurality" {
+			votes[ballot[0]]++
+			continue
+		}
+		for _, optionID := range ballot {
+			votes[optionID]++
+		}
+	}
+
+	round := models.BallotRound{Round: 1, Counts: countOptions(options, votes, nil), Eliminated: []string{}}
+	result.Rounds = append(result.Rounds, round)
+	result.Winners = leaders(round.Counts)
+	return result
+}
+
+// tallyRunoff runs instant-runoff rounds. Each round counts every ballot for
+// its highest-ranked continuing option; an option with a majority of the
+// non-exhausted ballots wins, otherwise the last-placed option is
+// eliminated. Options tied for last are eliminated together only when
+// their combined votes are below the next-lowest count, since then none of
+// them could overtake it; otherwise one is chosen by eliminateOne. If every
+// continuing option is tied they share the win.
+func tallyRunoff(result *models.BallotResult, options []models.BallotOption, ballots [][]string) {
+	continuing := map[string]bool{}
+	for _, o := range options {
+		continuing[o.ID] = true
+	}
+
+	for n := 1; len(continuing) > 0; n++ {
+		votes := map[string]int{}
+		exhausted := 0
+		for _, ballot := range ballots {
+			counted := false
+			for _, optionID := range ballot {
+				if continuing[optionID] {
+					votes[optionID]++
+					counted = true
+					break
+				}
+			}
+			if !counted {
+				exhausted++
+			}
+		}
+
+		round := models.BallotRound{
+			Round:      n,
+			Counts:     countOptions(options, votes, continuing),
+			Exhausted:  exhausted,
+			Eliminated: []string{},
+		}
+
+		active := len(ballots) - exhausted
+		if active == 0 {
+			result.Rounds = append(result.Rounds, round)
+			return
+		}
+
+		top := leaders(round.Counts)
+		if len(top) == 1 && votes[top[0]]*2 > active {
+			result.Rounds = append(result.Rounds, round)
+			result.Winners = top
+			return
+		}
+
+		lowest := round.Counts[0].Votes
+		for _, count := range round.Counts {
+			if count.Votes < lowest {
+				lowest = count.Votes
+			}
+		}
+		last := []string{}
+		nextLowest := -1
+		for _, count := range round.Counts {
+			if count.Votes == lowest {
+				last = append(last, count.OptionID)
+			} else if nextLowest < 0 || count.Votes < nextLowest {
+				nextLowest = count.Votes
+			}
+		}
+
+		if len(last) == len(round.Counts) {
+			result.Rounds = append(result.Rounds, round)
+			result.Winners = last
+			return
+		}
+
+		if len(last) > 1 && lowest*len(last) >= nextLowest {
+			var eliminated string
+			eliminated, round.TieBreak = eliminateOne(last, result.Rounds)
+			last = []string{eliminated}
+		}
+
+		round.Eliminated = last
+		result.Rounds = append(result.Rounds, round)
+		for _, optionID := range last {
+			delete(continuing, optionID)
+		}
+	}
+}
+
+// eliminateOne picks which of the options tied for last to eliminate: the
+// one with the fewest votes in the latest earlier round that tells them
+// apart, or failing that the one listed last on the ballot. tied is in
+// ballot order. It returns the option and the rule that decided.
+func eliminateOne(tied []string, previous []models.BallotRound) (string, string) {
+	candidates := tied
+	for i := len(previous) - 1; i >= 0 && len(candidates) > 1; i-- {
+		votes := map[string]int{}
+		for _, count := range previous[i].Counts {
+			votes[count.OptionID] = count.Votes
+		}
+
+		fewest := votes[candidates[0]]
+		for _, optionID := range candidates {
+			if votes[optionID] < fewest {
+				fewest = votes[optionID]
+			}
+		}
+		lowest := []string{}
+		for _, optionID := range candidates {
+			if votes[optionID] == fewest {
+				lowest = append(lowest, optionID)
+			}
+		}
+
+		if len(lowest) < len(candidates) {
+			candidates = lowest
+			if len(candidates) == 1 {
+				return candidates[0], "previous_round"
+			}
+		}
+	}
+
+	return candidates[len(candidates)-1], "ballot_order"
+}
+
+// countOptions lists vote counts in ballot order, restricted to the
+// continuing options when that set is given.
+func countOptions(options []models.BallotOption, votes map[string]int, continuing map[string]bool) []models.BallotOptionCount {
+	counts := []models.BallotOptionCount{}
+	for _, o := range options {
+		if continuing != nil && !continuing[o.ID] {
+			continue
+		}
+		counts = append(counts, models.BallotOptionCount{
+			OptionID: o.ID,
+			Label:    o.Label,
+			Votes:    votes[o.ID],
+		})
+	}
+	return counts
+}
+
+// leaders returns the options with the most votes, or none when nobody voted.
+func leaders(counts []models.BallotOptionCount) []string {
+	best := 0
+	for _, count := range counts {
+		if count.Votes > best {
+			best = count.Votes
+		}
+	}
+
+	ids := []string{}
+	if best == 0 {
+		return ids
+	}
+	for _, count := range counts {
+		if count.Votes == best {
+			ids = append(ids, count.OptionID)
+		}
+	}
+	return ids
+}
+
+// LoadBallot reads a ballot and its options.
+func LoadBallot(db *sql.DB, ballotID string) (*models.Ballot, error) {
+	var b models.Ballot
+	err := db.QueryRow(`
+		SELECT b.id, b.title, b.description, b.method, b.max_choices, b.status,
+			b.closes_at, b.created_by, b.created_at,
+			(SELECT COUNT(*) FROM ballot_votes bv WHERE bv.ballot_id = b.id)
+		FROM ballots b
+		WHERE b.id = $1
+	`, ballotID).Scan(
+		&b.ID, &b.Title, &b.Description, &b.Method, &b.MaxChoices, &b.Status,
+		&b.ClosesAt, &b.CreatedBy, &b.CreatedAt, &b.TotalVotes,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrBallotNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT id, policy_id, label, position
+		FROM ballot_options
+		WHERE ballot_id = $1
+		ORDER BY position ASC
+	`, ballotID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	b.Options = []models.BallotOption{}
+	for rows.Next() {
+		var o models.BallotOption
+		if err := rows.Scan(&o.ID, &o.PolicyID, &o.Label, &o.Position); err != nil {
+			return nil, err
+		}
+		b.Options = append(b.Options, o)
+	}
+
+	return &b, rows.Err()
+}
+
+// TallyBallot loads every submitted ballot and tallies it with the ballot's
+// method.
+func TallyBallot(db *sql.DB, ballot *models.Ballot) (*models.BallotResult, error) {
+	rows, err := db.Query(`
+		SELECT bc.ballot_vote_id, bc.option_id
+		FROM ballot_choices bc
+		JOIN ballot_votes bv ON bc.ballot_vote_id = bv.id
+		WHERE bv.ballot_id = $1
+		ORDER BY bc.ballot_vote_id, bc.rank ASC
+	`, ballot.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ballots := [][]string{}
+	lastVoteID := ""
+	for rows.Next() {
+		var voteID, optionID string
+		if err := rows.Scan(&voteID, &optionID); err != nil {
+			return nil, err
+		}
+		if voteID != lastVoteID {
+			ballots = append(ballots, []string{})
+			lastVoteID = voteID
+		}
+		ballots[len(ballots)-1] = append(ballots[len(ballots)-1], optionID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := Tally(ballot.Method, ballot.Options, ballots)
+	result.BallotID = ballot.ID
+	return &result, nil
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"vote/internal/models"
+)
+
+func ballotOptions(ids ...string) []models.BallotOption {
+	options := make([]models.BallotOption, len(ids))
+	for i, id := range ids {
+		options[i] = models.BallotOption{ID: id, Label: id, Position: i + 1}
+	}
+	return options
+}
+
+// repeat returns n copies of a ballot ranking the given options.
+func repeat(n int, ranking string) [][]string {
+	ballots := make([][]string, n)
+	for i := range ballots {
+		if ranking != "" {
+			ballots[i] = strings.Split(ranking, ",")
+		} else {
+			ballots[i] = []string{}
+		}
+	}
+	return ballots
+}
+
+func concat(groups ...[][]string) [][]string {
+	ballots := [][]string{}
+	for _, group := range groups {
+		ballots = append(ballots, group...)
+	}
+	return ballots
+}
+
+func TestTally(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		options    []string
+		ballots    [][]string
+		winners    []string
+		rounds     int
+		eliminated [][]string
+		tieBreaks  []string
+		exhausted  []int
+	}{
+		{
+			name:    "plurality counts first choices",
+			method:  "plurality",
+			options: []string{"A", "B", "C"},
+			ballots: concat(repeat(3, "A,B"), repeat(2, "B,A"), repeat(1, "C")),
+			winners: []string{"A"},
+			rounds:  1,
+		},
+		{
+			name:    "plurality tie shares the win",
+			method:  "plurality",
+			options: []string{"A", "B"},
+			ballots: concat(repeat(2, "A"), repeat(2, "B")),
+			winners: []string{"A", "B"},
+			rounds:  1,
+		},
+		{
+			name:    "approval counts every choice",
+			method:  "approval",
+			options: []string{"A", "B", "C"},
+			ballots: concat(repeat(3, "A"), repeat(2, "B,C"), repeat(2, "C,B")),
+			winners: []string{"B", "C"},
+			rounds:  1,
+		},
+		{
+			name:    "no ballots has no winner",
+			method:  "plurality",
+			options: []string{"A", "B"},
+			ballots: [][]string{},
+			winners: []string{},
+			rounds:  1,
+		},
+		{
+			name:       "irv majority in the first round",
+			method:     "irv",
+			options:    []string{"A", "B", "C"},
+			ballots:    concat(repeat(6, "A,B"), repeat(3, "B"), repeat(2, "C,B")),
+			winners:    []string{"A"},
+			rounds:     1,
+			eliminated: [][]string{{}},
+		},
+		{
+			name:       "irv transfers the eliminated option's votes",
+			method:     "irv",
+			options:    []string{"A", "B", "C"},
+			ballots:    concat(repeat(4, "A"), repeat(3, "B,C"), repeat(2, "C,B")),
+			winners:    []string{"B"},
+			rounds:     2,
+			eliminated: [][]string{{"C"}, {}},
+		},
+		{
+			name:       "irv tied last with enough votes to matter eliminates one",
+			method:     "irv",
+			options:    []string{"A", "B", "C"},
+			ballots:    concat(repeat(5, "A"), repeat(4, "B"), repeat(4, "C,B")),
+			winners:    []string{"B"},
+			rounds:     2,
+			eliminated: [][]string{{"C"}, {}},
+			tieBreaks:  []string{"ballot_order", ""},
+		},
+		{
+			name:       "irv tied last below the next count are eliminated together",
+			method:     "irv",
+			options:    []string{"A", "B", "C", "D"},
+			ballots:    concat(repeat(5, "A"), repeat(4, "B"), repeat(1, "C,B"), repeat(1, "D,B")),
+			winners:    []string{"B"},
+			rounds:     2,
+			eliminated: [][]string{{"C", "D"}, {}},
+			tieBreaks:  []string{"", ""},
+		},
+		{
+			name:       "irv tie broken by the previous round",
+			method:     "irv",
+			options:    []string{"A", "B", "C", "D"},
+			ballots:    concat(repeat(6, "A"), repeat(3, "B"), repeat(2, "C"), repeat(1, "D,C")),
+			winners:    []string{"A"},
+			rounds:     3,
+			eliminated: [][]string{{"D"}, {"C"}, {}},
+			tieBreaks:  []string{"", "previous_round", ""},
+			exhausted:  []int{0, 0, 3},
+		},
+		{
+			name:       "irv exhausted ballots leave the majority threshold",
+			method:     "irv",
+			options:    []string{"A", "B", "C"},
+			ballots:    concat(repeat(4, "A"), repeat(3, "B"), repeat(2, "C")),
+			winners:    []string{"A"},
+			rounds:     2,
+			eliminated: [][]string{{"C"}, {}},
+			exhausted:  []int{0, 2},
+		},
+		{
+			name:       "irv all tied in the final round share the win",
+			method:     "irv",
+			options:    []string{"A", "B", "C"},
+			ballots:    concat(repeat(3, "A"), repeat(3, "B"), repeat(1, "C,A"), repeat(1, "C,B")),
+			winners:    []string{"A", "B"},
+			rounds:     2,
+			eliminated: [][]string{{"C"}, {}},
+		},
+		{
+			name:       "irv with every ballot exhausted has no winner",
+			method:     "irv",
+			options:    []string{"A", "B"},
+			ballots:    repeat(2, ""),
+			winners:    []string{},
+			rounds:     1,
+			eliminated: [][]string{{}},
+			exhausted:  []int{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Tally(tt.method, ballotOptions(tt.options...), tt.ballots)
+
+			if !reflect.DeepEqual(result.Winners, tt.winners) {
+				t.Errorf("winners = %v, want %v", result.Winners, tt.winners)
+			}
+			if len(result.Rounds) != tt.rounds {
+				t.Fatalf("rounds = %d, want %d", len(result.Rounds), tt.rounds)
+			}
+			for i, round := range result.Rounds {
+				if tt.eliminated != nil && !reflect.DeepEqual(round.Eliminated, tt.eliminated[i]) {
+					t.Errorf("round %d eliminated = %v, want %v", i+1, round.Eliminated, tt.eliminated[i])
+				}
+				if tt.tieBreaks != nil && round.TieBreak != tt.tieBreaks[i] {
+					t.Errorf("round %d tie break = %q, want %q", i+1, round.TieBreak, tt.tieBreaks[i])
+				}
+				if tt.exhausted != nil && round.Exhausted != tt.exhausted[i] {
+					t.Errorf("round %d exhausted = %d, want %d", i+1, round.Exhausted, tt.exhausted[i])
+				}
+			}
+		})
+	}
+}
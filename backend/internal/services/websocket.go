@@ -1,112 +1,654 @@
 package services
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"log"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gofiber/websocket/v2"
 )
 
+// Topics a client can subscribe to. Policy, category and user topics take
+// an ID suffix; TopicMine is resolved to the subscriber's own user topic,
+// which carries updates about the policies they submitted. TopicPolicies
+// carries tallies and status changes for every public policy, plus
+// policies appearing on or leaving the public list, so a listing can
+// follow all its cards with one subscription.
+const (
+	TopicPolicyPrefix   = "policy:"
+	TopicCategoryPrefix = "category:"
+	TopicUserPrefix     = "user:"
+	TopicModeration     = "moderation"
+	TopicMine           = "mine"
+	TopicPolicies       = "policies"
+)
+
+// maxSubscriptions bounds how many topics one connection may follow.
+const maxSubscriptions = 100
+
+// Connection tuning. Each client has its own bounded send queue drained by
+// a writer goroutine, so a slow reader never holds up the hub or the
+// request that published an update; a client whose queue fills is
+// disconnected and can reconnect. Pings keep idle connections alive through proxies and
+// detect dead peers, which must answer within pongWait.
+const (
+	sendQueueSize  = 64
+	broadcastQueue = 1024
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = pongWait * 9 / 10
+	maxMessageSize = 4096
+	replayBuffer   = 256
+)
+
+var (
+	ErrInvalidTopic     = errors.New("invalid topic")
+	ErrTopicForbidden   = errors.New("not allowed to subscribe to this topic")
+	ErrTooManyTopics    = errors.New("too many subscriptions")
+	ErrUnknownWSCommand = errors.New("unknown action")
+	ErrTopicUnavailable = errors.New("could not check access to this topic")
+)
+
+// WSClient is an authenticated socket, the topics it follows and its
+// queue of outbound messages.
+type WSClient struct {
+	Conn   *websocket.Conn
+	UserID string
+	Role   string
+	send   chan wsFrame
+	done   chan struct{}
+	topics map[string]bool
+	mutex  sync.RWMutex
+
+	// expires is when the client's token runs out, after which the
+	// connection is closed; the zero time never passes. db is where the
+	// client's role is looked up again before it joins the moderation feed.
+	expires time.Time
+	db      *sql.DB
+
+	// resumeFrom is the last event ID an event stream client saw; the
+	// hub replays what it missed when it registers.
+	resumeFrom string
+}
+
+type wsBroadcast struct {
+	seq    uint64
+	topics []string
+	data   []byte
+}
+
+// wsFrame is one queued message. Broadcasts carry an event ID so event
+// stream clients can resume; replies to a single client have none.
+type wsFrame struct {
+	id   string
+	data []byte
+}
+
+// WebSocketHub routes published messages to the clients following their
+// topics. Messages go out through Broker, so every instance sharing it
+// relays them to its own clients. Only Run touches the client set; it
+// signals a client to stop by closing its done channel and never closes
+// a send queue, which other goroutines may still be sending on.
 type WebSocketHub struct {
-	clients    map[*websocket.Conn]bool
-	broadcast  chan []byte
-	register   chan *websocket.Conn
-	unregister chan *websocket.Conn
-	mutex      sync.RWMutex
+	DB         *sql.DB
+	Broker     Broker
+	clients    map[*WSClient]bool
+	broadcast  chan wsBroadcast
+	register   chan *WSClient
+	unregister chan *WSClient
+	quit       chan struct{}
+	done       chan struct{}
+	dropped    atomic.Uint64
+	queueSize  int
+
+	// stream identifies this hub's event sequence, so IDs handed out
+	// before a restart or by another instance are not mistaken for ours.
+	stream  string
+	seq     uint64
+	history []wsBroadcast
 }
 
 type WSMessage struct {
 	Type     string      `json:"type"`
 	PolicyID string      `json:"policy_id,omitempty"`
+	Topic    string      `json:"topic,omitempty"`
 	Data     interface{} `json:"data,omitempty"`
 }
 
-func NewWebSocketHub() *WebSocketHub {
-	return &WebSocketHub{
-		clients:    make(map[*websocket.Conn]bool),
-		broadcast:  make(chan []byte),
-		register:   make(chan *websocket.Conn),
-		unregister: make(chan *websocket.Conn),
+// WSCommand is what clients send to manage their subscriptions.
+type WSCommand struct {
+	Action string `json:"action"`
+	Topic  string `json:"topic"`
+}
+
+// NewWebSocketHub creates a hub that looks up each policy's category and
+// author in db to route its updates to the matching topics. A nil broker
+// keeps messages within this process.
+func NewWebSocketHub(db *sql.DB, broker Broker) *WebSocketHub {
+	if broker == nil {
+		broker = NewLocalBroker()
 	}
+
+	h := &WebSocketHub{
+		DB:         db,
+		Broker:     broker,
+		stream:     strconv.FormatInt(time.Now().UnixNano(), 36),
+		clients:    make(map[*WSClient]bool),
+		broadcast:  make(chan wsBroadcast, broadcastQueue),
+		register:   make(chan *WSClient),
+		unregister: make(chan *WSClient),
+		quit:       make(chan struct{}),
+		done:       make(chan struct{}),
+		queueSize:  sendQueueSize,
+	}
+	broker.Start(h.deliver)
+	return h
 }
 
 func (h *WebSocketHub) Run() {
+	defer close(h.done)
+
 	for {
 		select {
 		case client := <-h.register:
-			h.mutex.Lock()
 			h.clients[client] = true
-			h.mutex.Unlock()
+			if client.resumeFrom != "" {
+				h.replay(client)
+			}
 
 		case client := <-h.unregister:
-			h.mutex.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				client.Close()
-			}
-			h.mutex.Unlock()
+			h.remove(client)
 
 		case message := <-h.broadcast:
-			h.mutex.RLock()
+			h.seq++
+			message.seq = h.seq
+			h.history = append(h.history, message)
+			if len(h.history) > replayBuffer {
+				h.history = h.history[1:]
+			}
+
+			frame := wsFrame{id: h.eventID(message.seq), data: message.data}
 			for client := range h.clients {
-				err := client.WriteMessage(websocket.TextMessage, message)
-				if err != nil {
-					client.Close()
-					delete(h.clients, client)
+				if !client.follows(message.topics) {
+					continue
+				}
+				select {
+				case client.send <- frame:
+				default:
+					h.drop(client)
 				}
 			}
-			h.mutex.RUnlock()
+
+		case <-h.quit:
+			for client := range h.clients {
+				h.remove(client)
+			}
+			return
+		}
+	}
+}
+
+// Shutdown disconnects every client with a going-away close frame, stops
+// Run and closes the broker. Messages published afterwards are dropped.
+func (h *WebSocketHub) Shutdown() {
+	if err := h.Broker.Close(); err != nil {
+		log.Println("Failed to close event broker:", err)
+	}
+
+	select {
+	case <-h.quit:
+	default:
+		close(h.quit)
+	}
+	<-h.done
+}
+
+// DroppedMessages counts messages discarded because the hub's own queue
+// was full.
+func (h *WebSocketHub) DroppedMessages() uint64 {
+	return h.dropped.Load()
+}
+
+func (h *WebSocketHub) eventID(seq uint64) string {
+	return h.stream + "-" + strconv.FormatUint(seq, 10)
+}
+
+// replay queues the broadcasts a resuming client missed. If they are no
+// longer all buffered, came from another stream, or would overflow its
+// queue, the client is told to resync and reload instead.
+func (h *WebSocketHub) replay(client *WSClient) {
+	var missed []wsFrame
+	complete := false
+
+	stream, after, found := strings.Cut(client.resumeFrom, "-")
+	seq, err := strconv.ParseUint(after, 10, 64)
+	if found && err == nil && stream == h.stream && seq <= h.seq {
+		complete = len(h.history) == 0 || h.history[0].seq <= seq+1
+		for _, message := range h.history {
+			if message.seq > seq && client.follows(message.topics) {
+				missed = append(missed, wsFrame{id: h.eventID(message.seq), data: message.data})
+			}
 		}
 	}
+
+	if !complete || len(missed) > cap(client.send) {
+		data, _ := json.Marshal(WSMessage{Type: "resync"})
+		missed = []wsFrame{{id: h.eventID(h.seq), data: data}}
+	}
+
+	for _, frame := range missed {
+		client.send <- frame
+	}
 }
 
-func (h *WebSocketHub) HandleConnection(c *websocket.Conn) {
-	h.register <- c
+// remove forgets a client and tells its writer to say goodbye and hang up.
+func (h *WebSocketHub) remove(client *WSClient) {
+	if _, ok := h.clients[client]; ok {
+		delete(h.clients, client)
+		close(client.done)
+	}
+}
+
+// drop disconnects a client that is not keeping up. Its writer may be
+// stuck on a full socket, so the connection is closed outright rather than
+// waiting for the write deadline.
+func (h *WebSocketHub) drop(client *WSClient) {
+	h.remove(client)
+	if client.Conn != nil {
+		client.Conn.Close()
+	}
+}
+
+// HandleConnection serves an authenticated socket until it closes. It
+// reads subscription commands on the calling goroutine and writes from a
+// second one, which is the only writer the connection has. The socket is
+// closed once expires, the expiry of the token it was opened with, passes.
+func (h *WebSocketHub) HandleConnection(c *websocket.Conn, userID, role string, expires time.Time) {
+	client := &WSClient{
+		Conn:    c,
+		UserID:  userID,
+		Role:    role,
+		send:    make(chan wsFrame, h.queueSize),
+		done:    make(chan struct{}),
+		topics:  map[string]bool{},
+		expires: expires,
+		db:      h.DB,
+	}
+
+	select {
+	case h.register <- client:
+	case <-h.quit:
+		c.Close()
+		return
+	}
+
+	written := make(chan struct{})
+	go func() {
+		client.writePump()
+		close(written)
+	}()
+
+	client.readPump()
+
+	select {
+	case h.unregister <- client:
+	case <-h.done:
+	}
+	<-written
+}
+
+func (c *WSClient) readPump() {
+	c.Conn.SetReadLimit(maxMessageSize)
+	c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.Conn.SetPongHandler(func(string) error {
+		return c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, raw, err := c.Conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var cmd WSCommand
+		if err := json.Unmarshal(raw, &cmd); err != nil {
+			c.reply(WSMessage{Type: "error", Data: map[string]string{"error": "Invalid message"}})
+			continue
+		}
+
+		topic, err := c.apply(cmd)
+		if err != nil {
+			c.reply(WSMessage{Type: "error", Topic: cmd.Topic, Data: map[string]string{"error": err.Error()}})
+			continue
+		}
+		c.reply(WSMessage{Type: cmd.Action + "d", Topic: topic})
+	}
+}
+
+// writePump drains the send queue and pings on idle. When the hub closes
+// done, or the client's token expires, it sends a close frame and hangs up,
+// which also ends readPump.
+func (c *WSClient) writePump() {
+	ticker := time.NewTicker(pingPeriod)
 	defer func() {
-		h.unregister <- c
+		ticker.Stop()
+		c.Conn.Close()
 	}()
 
 	for {
-		_, _, err := c.ReadMessage()
+		select {
+		case frame := <-c.send:
+			c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.Conn.WriteMessage(websocket.TextMessage, frame.data); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if c.expired() {
+				c.Conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "Token expired"))
+				return
+			}
+			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+
+		case <-c.done:
+			c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.Conn.WriteMessage(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+			return
+		}
+	}
+}
+
+// apply runs a subscription command, returning the resolved topic.
+func (c *WSClient) apply(cmd WSCommand) (string, error) {
+	topic, err := authorizeTopic(c, cmd.Topic)
+	if err != nil {
+		return "", err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	switch cmd.Action {
+	case "subscribe":
+		if !c.topics[topic] && len(c.topics) >= maxSubscriptions {
+			return "", ErrTooManyTopics
+		}
+		c.topics[topic] = true
+	case "unsubscribe":
+		delete(c.topics, topic)
+	default:
+		return "", ErrUnknownWSCommand
+	}
+
+	return topic, nil
+}
+
+// reply queues an acknowledgement or error for this client alone. A reply
+// that does not fit in the queue, or comes after the hub let the client
+// go, is dropped.
+func (c *WSClient) reply(msg WSMessage) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+
+	select {
+	case c.send <- wsFrame{data: data}:
+	case <-c.done:
+	default:
+	}
+}
+
+// expired reports whether the token the client connected with has run out.
+func (c *WSClient) expired() bool {
+	return !c.expires.IsZero() && time.Now().After(c.expires)
+}
+
+// currentRole is the client's role as the users table has it now, so that
+// a reviewer who was demoted or deactivated after signing in loses access.
+// A deactivated or deleted user has no role. Without a database the role
+// from the token is all there is.
+func (c *WSClient) currentRole() (string, error) {
+	if c.db == nil {
+		return c.Role, nil
+	}
+
+	var role string
+	var active bool
+	err := c.db.QueryRow(`SELECT role, is_active FROM users WHERE id = $1`, c.UserID).Scan(&role, &active)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		log.Printf("Failed to check role of user %s: %v", c.UserID, err)
+		return "", ErrTopicUnavailable
+	}
+	if !active {
+		return "", nil
+	}
+	return role, nil
+}
+
+// authorizeTopic checks that a client may follow topic and resolves
+// TopicMine to its user topic. Policy and category topics are open to any
+// signed-in user, matching the REST endpoints; the moderation feed is for
+// reviewers only, checked against their current role, and user topics only
+// for their own user.
+func authorizeTopic(client *WSClient, topic string) (string, error) {
+	switch {
+	case topic == TopicMine:
+		return TopicUserPrefix + client.UserID, nil
+	case topic == TopicPolicies:
+		return topic, nil
+	case topic == TopicModeration:
+		role, err := client.currentRole()
 		if err != nil {
-			break
+			return "", err
+		}
+		if role != "admin" && role != "superuser" {
+			return "", ErrTopicForbidden
+		}
+		return topic, nil
+	case strings.HasPrefix(topic, TopicUserPrefix):
+		if topic != TopicUserPrefix+client.UserID {
+			return "", ErrTopicForbidden
 		}
+		return topic, nil
+	case strings.HasPrefix(topic, TopicPolicyPrefix), strings.HasPrefix(topic, TopicCategoryPrefix):
+		id := topic[strings.Index(topic, ":")+1:]
+		if id == "" || len(id) > 64 {
+			return "", ErrInvalidTopic
+		}
+		return topic, nil
 	}
+	return "", ErrInvalidTopic
 }
 
-func (h *WebSocketHub) BroadcastVoteUpdate(policyID string, upvotes, downvotes int) {
-	msg := WSMessage{
-		Type:     "vote_update",
-		PolicyID: policyID,
-		Data: map[string]interface{}{
-			"upvotes":   upvotes,
-			"downvotes": downvotes,
-		},
+func (c *WSClient) follows(topics []string) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	for _, topic := range topics {
+		if c.topics[topic] {
+			return true
+		}
+	}
+	return false
+}
+
+// policyRoute is what the hub needs to know about a policy to route
+// messages about it.
+type policyRoute struct {
+	categoryID string
+	authorID   string
+	status     string
+}
+
+// lookupRoute reads a policy's route, which is empty when the policy is
+// gone or the hub has no database.
+func (h *WebSocketHub) lookupRoute(policyID string) policyRoute {
+	var route policyRoute
+	if h.DB == nil {
+		return route
+	}
+
+	var categoryID, submittedBy *string
+	err := h.DB.QueryRow(`SELECT category_id, submitted_by, status FROM policies WHERE id = $1`, policyID).Scan(&categoryID, &submittedBy, &route.status)
+	if err != nil {
+		return route
 	}
 
+	if categoryID != nil {
+		route.categoryID = *categoryID
+	}
+	if submittedBy != nil {
+		route.authorID = *submittedBy
+	}
+	return route
+}
+
+// topics returns the topics an update about a policy is routed to: the
+// policy itself, its author's submissions and, when students can see it,
+// its category.
+func (r policyRoute) topics(policyID string, public bool) []string {
+	topics := []string{TopicPolicyPrefix + policyID}
+	if r.authorID != "" {
+		topics = append(topics, TopicUserPrefix+r.authorID)
+	}
+	if public && r.categoryID != "" {
+		topics = append(topics, TopicCategoryPrefix+r.categoryID)
+	}
+	return topics
+}
+
+func (h *WebSocketHub) publish(topics []string, msg WSMessage) {
 	data, err := json.Marshal(msg)
 	if err != nil {
 		log.Println("Failed to marshal websocket message:", err)
 		return
 	}
 
-	h.broadcast <- data
+	h.Broker.Publish(topics, data)
 }
 
-func (h *WebSocketHub) BroadcastPolicyUpdate(policyID, status string) {
-	msg := WSMessage{
-		Type:     "policy_update",
+// deliver queues a message from the broker for this instance's clients.
+// It never blocks the caller, which is usually a request handler; if the
+// hub has fallen this far behind the update is lost.
+func (h *WebSocketHub) deliver(topics []string, data []byte) {
+	select {
+	case h.broadcast <- wsBroadcast{topics: topics, data: data}:
+	default:
+		h.dropped.Add(1)
+	}
+}
+
+func (h *WebSocketHub) BroadcastVoteUpdate(policyID string, upvotes, downvotes int) {
+	h.publish(append(h.lookupRoute(policyID).topics(policyID, true), TopicPolicies), WSMessage{
+		Type:     "vote_update",
 		PolicyID: policyID,
 		Data: map[string]interface{}{
-			"status": status,
+			"upvotes":   upvotes,
+			"downvotes": downvotes,
 		},
-	}
+	})
+}
 
-	data, err := json.Marshal(msg)
-	if err != nil {
-		return
+// HandleEvent translates a domain event into messages for the clients
+// that should hear about it. Pending and withdrawn policies only reach
+// their author and the moderation feed.
+func (h *WebSocketHub) HandleEvent(e DomainEvent) {
+	route := policyRoute{categoryID: e.CategoryID, authorID: e.AuthorID, status: e.Status}
+	if e.Type != EventPolicyDeleted {
+		route = h.lookupRoute(e.PolicyID)
 	}
+	public := IsPublicStatus(route.status)
 
-	h.broadcast <- data
+	switch e.Type {
+	case EventPolicyCreated:
+		h.publish(append(route.topics(e.PolicyID, public), TopicModeration), WSMessage{
+			Type:     "policy_created",
+			PolicyID: e.PolicyID,
+			Data: map[string]interface{}{
+				"status": route.status,
+			},
+		})
+
+	case EventPolicyApproved:
+		h.publish([]string{TopicPolicies}, WSMessage{
+			Type:     "policy_approved",
+			PolicyID: e.PolicyID,
+			Data: map[string]interface{}{
+				"category_id": route.categoryID,
+			},
+		})
+
+	case EventPolicyPublished:
+		h.publish([]string{TopicPolicies}, WSMessage{
+			Type:     "policy_published",
+			PolicyID: e.PolicyID,
+			Data: map[string]interface{}{
+				"status":      e.Status,
+				"category_id": route.categoryID,
+			},
+		})
+
+	case EventStatusChanged:
+		public = public || IsPublicStatus(e.PreviousStatus)
+		topics := append(route.topics(e.PolicyID, public), TopicModeration)
+		if public {
+			topics = append(topics, TopicPolicies)
+		}
+		h.publish(topics, WSMessage{
+			Type:     "policy_update",
+			PolicyID: e.PolicyID,
+			Data: map[string]interface{}{
+				"status":          e.Status,
+				"previous_status": e.PreviousStatus,
+			},
+		})
+
+	case EventPolicyDeleted:
+		topics := append(route.topics(e.PolicyID, public), TopicModeration)
+		if public {
+			topics = append(topics, TopicPolicies)
+		}
+		h.publish(topics, WSMessage{
+			Type:     "policy_deleted",
+			PolicyID: e.PolicyID,
+		})
+
+	case EventCommentAdded:
+		h.publish([]string{TopicPolicyPrefix + e.PolicyID}, WSMessage{
+			Type:     "comment_added",
+			PolicyID: e.PolicyID,
+			Data: map[string]interface{}{
+				"comment_id": e.CommentID,
+			},
+		})
+
+	case EventCategoryChanged:
+		topics := append(route.topics(e.PolicyID, public), TopicModeration)
+		if public && e.PreviousCategoryID != "" {
+			topics = append(topics, TopicCategoryPrefix+e.PreviousCategoryID)
+		}
+		h.publish(topics, WSMessage{
+			Type:     "category_changed",
+			PolicyID: e.PolicyID,
+			Data: map[string]interface{}{
+				"category_id":          route.categoryID,
+				"previous_category_id": e.PreviousCategoryID,
+			},
+		})
+	}
 }
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"bytes"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	fws "github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+)
+
+// startHub serves a hub with a small send queue on a local port and
+// returns the WebSocket URL.
+func startHub(t *testing.T, queueSize int) (*WebSocketHub, string) {
+	t.Helper()
+
+	hub := NewWebSocketHub(nil, nil)
+	hub.queueSize = queueSize
+	go hub.Run()
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/ws", websocket.New(func(c *websocket.Conn) {
+		hub.HandleConnection(c, "user-1", "student", time.Time{})
+	}))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go app.Listener(ln)
+
+	t.Cleanup(func() {
+		hub.Shutdown()
+		app.Shutdown()
+	})
+
+	return hub, "ws://" + ln.Addr().String() + "/ws"
+}
+
+// dialSubscribed connects and waits until the hub confirms the subscription.
+func dialSubscribed(t *testing.T, url, topic string) *fws.Conn {
+	t.Helper()
+
+	conn, _, err := fws.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	if err := conn.WriteJSON(WSCommand{Action: "subscribe", Topic: topic}); err != nil {
+		t.Fatal(err)
+	}
+
+	var reply WSMessage
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := conn.ReadJSON(&reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Type != "subscribed" {
+		t.Fatalf("subscribe reply = %q, want subscribed", reply.Type)
+	}
+
+	return conn
+}
+
+// A client that never reads must be dropped once its queue fills, without
+// delaying delivery to clients that keep up.
+func TestSlowClientDoesNotStallOthers(t *testing.T) {
+	const (
+		queueSize = 4
+		messages  = 400
+		deadline  = 2 * time.Second
+	)
+
+	hub, url := startHub(t, queueSize)
+	topics := []string{TopicPolicyPrefix + "p1"}
+
+	slow := dialSubscribed(t, url, topics[0])
+	healthy := dialSubscribed(t, url, topics[0])
+
+	// Large messages fill the slow client's socket buffers, after which its
+	// writer blocks and its queue backs up.
+	padding := strings.Repeat("x", 32*1024)
+
+	for i := 0; i < messages; i++ {
+		hub.publish(topics, WSMessage{Type: "vote_update", PolicyID: "p1", Data: map[string]interface{}{
+			"seq":     i,
+			"padding": padding,
+		}})
+
+		healthy.SetReadDeadline(time.Now().Add(deadline))
+		var msg struct {
+			Data struct {
+				Seq int `json:"seq"`
+			} `json:"data"`
+		}
+		if err := healthy.ReadJSON(&msg); err != nil {
+			t.Fatalf("healthy client: message %d: %v", i, err)
+		}
+		if msg.Data.Seq != i {
+			t.Fatalf("healthy client got message %d, want %d", msg.Data.Seq, i)
+		}
+	}
+
+	// The slow client gets what made it into its buffers, then the hub
+	// hangs up on it.
+	received := 0
+	slow.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, data, err := slow.ReadMessage()
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				t.Fatal("slow client was not disconnected")
+			}
+			break
+		}
+		if bytes.Contains(data, []byte(`"vote_update"`)) {
+			received++
+		}
+	}
+	t.Logf("slow client received %d of %d messages before being dropped", received, messages)
+	if received >= messages {
+		t.Fatalf("slow client received all %d messages; expected to be dropped", messages)
+	}
+
+	if dropped := hub.DroppedMessages(); dropped != 0 {
+		t.Errorf("hub dropped %d messages", dropped)
+	}
+}
+
+// Publishing is what request handlers such as CreateVote do after every
+// vote, so it must stay fast however many clients have stopped reading.
+func TestPublishLatencyWithStalledClients(t *testing.T) {
+	const (
+		queueSize = 4
+		stalled   = 8
+		messages  = 1000
+		maxP99    = time.Millisecond
+	)
+
+	hub, url := startHub(t, queueSize)
+	topics := []string{TopicPolicies}
+
+	stalledConns := make([]*fws.Conn, stalled)
+	for i := range stalledConns {
+		stalledConns[i] = dialSubscribed(t, url, topics[0])
+	}
+	healthy := dialSubscribed(t, url, topics[0])
+
+	padding := strings.Repeat("x", 8*1024)
+	latencies := make([]time.Duration, messages)
+
+	for i := 0; i < messages; i++ {
+		msg := WSMessage{Type: "vote_update", PolicyID: "p1", Data: map[string]interface{}{
+			"seq":     i,
+			"padding": padding,
+		}}
+
+		start := time.Now()
+		hub.publish(topics, msg)
+		latencies[i] = time.Since(start)
+
+		// Waiting for the healthy client keeps the publisher from simply
+		// outrunning the hub, so a full hub queue means a stall.
+		healthy.SetReadDeadline(time.Now().Add(2 * time.Second))
+		if _, _, err := healthy.ReadMessage(); err != nil {
+			t.Fatalf("healthy client: message %d: %v", i, err)
+		}
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	p99 := latencies[messages*99/100]
+	t.Logf("publish latency p50 %v, p99 %v, max %v", latencies[messages/2], p99, latencies[messages-1])
+	if p99 > maxP99 {
+		t.Errorf("publish p99 = %v with %d stalled clients, want under %v", p99, stalled, maxP99)
+	}
+
+	if dropped := hub.DroppedMessages(); dropped != 0 {
+		t.Errorf("hub dropped %d messages", dropped)
+	}
+
+	// The stalled clients really did back up: the hub hung up on them.
+	for i, conn := range stalledConns {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		for {
+			_, _, err := conn.ReadMessage()
+			if err == nil {
+				continue
+			}
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				t.Fatalf("stalled client %d was not disconnected", i)
+			}
+			break
+		}
+	}
+}
+
+// Replies racing a disconnect must neither send on a closed queue nor
+// block once the queue is full.
+func TestReplyAfterRemove(t *testing.T) {
+	client := &WSClient{
+		send:   make(chan wsFrame, 1),
+		done:   make(chan struct{}),
+		topics: map[string]bool{},
+	}
+
+	hub := &WebSocketHub{clients: map[*WSClient]bool{client: true}}
+	hub.remove(client)
+
+	replied := make(chan struct{})
+	go func() {
+		for i := 0; i < 3; i++ {
+			client.reply(WSMessage{Type: "subscribed"})
+		}
+		close(replied)
+	}()
+
+	select {
+	case <-replied:
+	case <-time.After(time.Second):
+		t.Fatal("reply blocked after the client was removed")
+	}
+}
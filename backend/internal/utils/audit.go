@@ -26,3 +26,18 @@ func (a *AuditLogger) Log(userID, action, entityType, entityID string, details i
 		log.Printf("Failed to write audit log: %v", err)
 	}
 }
+
+// LogSystem records an action taken by the server itself, such as a
+// scheduled job, with no acting user.
+func (a *AuditLogger) LogSystem(action, entityType, entityID string, details interface{}) {
+	detailsJSON, _ := json.Marshal(details)
+
+	_, err := a.DB.Exec(`
+		INSERT INTO audit_log (user_id, action, entity_type, entity_id, details)
+		VALUES (NULL, $1, $2, $3, $4)
+	`, action, entityType, entityID, detailsJSON)
+
+	if err != nil {
+		log.Printf("Failed to write audit log: %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+type keysetCursor struct {
+	Sort string   `json:"s"`
+	Keys []string `json:"k"`
+}
+
+// EncodeKeysetCursor packs the sort keys of a page's last row, ending with
+// its ID, into an opaque cursor tied to the sort mode they came from.
+func EncodeKeysetCursor(sort string, keys ...string) string {
+	raw, _ := json.Marshal(keysetCursor{Sort: sort, Keys: keys})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeKeysetCursor reverses EncodeKeysetCursor. It rejects cursors issued
+// for another sort mode or with the wrong number of keys, since their keys
+// would be compared against the wrong columns.
+func DecodeKeysetCursor(cursor, sort string, keys int) ([]string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	var decoded keysetCursor
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	if decoded.Sort != sort || len(decoded.Keys) != keys {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return decoded.Keys, nil
+}
@@ -0,0 +1,69 @@
+package utils
+
+import "regexp"
+
+var diffTokenPattern = regexp.MustCompile(`\s+|[^\s]+`)
+
+// DiffOp is a run of text that is equal in both versions, or only present
+// in the old ("delete") or the new ("insert") one.
+type DiffOp struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// DiffWords computes a word-level diff between two texts. Whitespace is kept
+// as its own token so joining the ops of either side reproduces that text.
+func DiffWords(a, b string) []DiffOp {
+	x := diffTokenPattern.FindAllString(a, -1)
+	y := diffTokenPattern.FindAllString(b, -1)
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := []DiffOp{}
+	add := func(op, text string) {
+		if n := len(ops); n > 0 && ops[n-1].Op == op {
+			ops[n-1].Text += text
+			return
+		}
+		ops = append(ops, DiffOp{Op: op, Text: text})
+	}
+
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			add("equal", x[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			add("delete", x[i])
+			i++
+		default:
+			add("insert", y[j])
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		add("delete", x[i])
+	}
+	for ; j < len(y); j++ {
+		add("insert", y[j])
+	}
+
+	return ops
+}
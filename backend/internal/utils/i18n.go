@@ -31,11 +31,14 @@ var translations = map[string]map[string]string{
 		"completed":        "Completed",
 		"on_hold":          "On Hold",
 		"cannot_implement": "Cannot Implement",
+		"withdrawn":        "Withdrawn",
+		"closed":           "Voting Closed",
 		"sort_by":          "Sort By",
 		"newest":           "Newest",
 		"oldest":           "Oldest",
 		"most_voted":       "Most Voted",
 		"trending":         "Trending",
+		"controversial":    "Controversial",
 		"export":           "Export",
 		"analytics":        "Analytics",
 		"dark_mode":        "Dark Mode",
@@ -71,11 +74,14 @@ var translations = map[string]map[string]string{
 		"completed":        "Finalizat",
 		"on_hold":          "În Așteptare",
 		"cannot_implement": "Nu Poate Fi Implementat",
+		"withdrawn":        "Retras",
+		"closed":           "Vot Închis",
 		"sort_by":          "Sortează După",
 		"newest":           "Cele Mai Noi",
 		"oldest":           "Cele Mai Vechi",
 		"most_voted":       "Cele Mai Votate",
 		"trending":         "Trending",
+		"controversial":    "Controversate",
 		"export":           "Exportă",
 		"analytics":        "Analize",
 		"dark_mode":        "Mod Întunecat",
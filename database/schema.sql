@@ -1,3 +1,7 @@
+-- The backend now manages this schema through versioned migrations in
+-- backend/internal/migrations (applied on startup or via `server migrate up`).
+-- This file is kept as a reference snapshot and for the sample data below.
+
 -- Enable UUID extension
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
@@ -12,6 +12,9 @@ let currentPolicyId = null;
 let currentAction = null;
 let policyToDelete = null;
 let selectedPolicies = new Set();
+let loadedPolicies = [];
+let policiesCursor = null;
+let splittingPolicyId = null;
 let editingPolicyId = null;
 
 function switchTab(tab) {
@@ -56,7 +59,10 @@ async function loadPolicies() {
   const queryString = status ? `?status=${status}` : '';
 
   try {
-    const policies = await apiRequest(`/admin/policies${queryString}`);
+    const data = await apiRequest(`/admin/policies${queryString}`);
+    const policies = data.policies;
+    loadedPolicies = policies;
+    policiesCursor = data.next_cursor;
 
     if (policies.length === 0) {
       policiesContainer.innerHTML = `
@@ -83,56 +89,10 @@ async function loadPolicies() {
           </tr>
         </thead>
         <tbody>
-          ${policies.map(policy => {
-            const totalVotes = (policy.upvotes || 0) + (policy.downvotes || 0);
-            const supportPercentage = totalVotes > 0 ? ((policy.upvotes || 0) / totalVotes * 100).toFixed(1) : 0;
-            
-            return `
-              <tr>
-                <td>
-                  <input type="checkbox" class="policy-checkbox" value="${policy.id}" onchange="togglePolicySelection('${policy.id}', this.checked)">
-                </td>
-                <td>
-                  <strong>${escapeHtml(policy.title)}</strong><br>
-                  <small style="color: var(--muted-foreground);">${escapeHtml(policy.description.substring(0, 100))}...</small>
-                  ${policy.admin_comment ? `<br><small style="color: var(--foreground); opacity: 0.8;">${escapeHtml(policy.admin_comment)}</small>` : ''}
-                </td>
-                <td>
-                  <span class="badge badge-${policy.status}">${policy.status}</span>
-                </td>
-                <td>
-                  <div style="min-width: 150px;">
-                    ${totalVotes > 0 ? `
-                      <div style="margin-bottom: 0.5rem;">
-                        <div style="display: flex; justify-content: space-between; font-size: 0.75rem; margin-bottom: 0.25rem;">
-                          <span>${policy.upvotes || 0} up</span>
-                          <span>${policy.downvotes || 0} down</span>
-                        </div>
-                        <div style="width: 100%; height: 6px; background: var(--muted); border-radius: 9999px; overflow: hidden; border: 1px solid var(--border);">
-                          <div style="height: 100%; background: var(--foreground); width: ${supportPercentage}%; transition: width 0.3s;"></div>
-                        </div>
-                      </div>
-                      <small style="color: var(--muted-foreground);">
-                        ${supportPercentage}% support (${totalVotes} total)
-                      </small>
-                    ` : `
-                      <small style="color: var(--muted-foreground);">No votes</small>
-                    `}
-                  </div>
-                </td>
-                <td>
-                  <small>${new Date(policy.created_at).toLocaleDateString()}</small>
-                </td>
-                <td>
-                  <div style="display: flex; gap: 0.5rem; flex-wrap: wrap;">
-                    ${renderActionButtons(policy)}
-                  </div>
-                </td>
-              </tr>
-            `;
-          }).join('')}
+          ${policies.map(renderPolicyRow).join('')}
         </tbody>
       </table>
+      ${renderLoadMorePolicies()}
     `;
 
   } catch (error) {
@@ -142,39 +102,163 @@ async function loadPolicies() {
   }
 }
 
+// Buttons for each status a policy can move to; the server decides which
+// transitions are allowed.
+const statusActions = {
+  approved: { label: 'Approve', style: 'btn-success' },
+  uncertain: { label: 'Uncertain', style: 'btn-warning' },
+  rejected: { label: 'Reject', style: 'btn-danger' },
+  closed: { label: 'Close Voting', style: 'btn-secondary' },
+  in_progress: { label: 'In Progress', style: 'btn-secondary' },
+  completed: { label: 'Complete', style: 'btn-success' },
+  on_hold: { label: 'Hold', style: 'btn-secondary' },
+  cannot_implement: { label: "Can't Do", style: 'btn-secondary' },
+};
+
+function renderPolicyRow(policy) {
+  const totalVotes = (policy.upvotes || 0) + (policy.downvotes || 0);
+  const supportPercentage = totalVotes > 0 ? ((policy.upvotes || 0) / totalVotes * 100).toFixed(1) : 0;
+  
+  return `
+    <tr>
+      <td>
+        <input type="checkbox" class="policy-checkbox" value="${policy.id}" onchange="togglePolicySelection('${policy.id}', this.checked)">
+      </td>
+      <td>
+        <strong>${escapeHtml(policy.title)}</strong><br>
+        <small style="color: var(--muted-foreground);">${escapeHtml(policy.description.substring(0, 100))}...</small>
+        ${policy.admin_comment ? `<br><small style="color: var(--foreground); opacity: 0.8;">${escapeHtml(policy.admin_comment)}</small>` : ''}
+      </td>
+      <td>
+        <span class="badge badge-${policy.status}">${policy.status}</span>
+      </td>
+      <td>
+        <div style="min-width: 150px;">
+          ${totalVotes > 0 ? `
+            <div style="margin-bottom: 0.5rem;">
+              <div style="display: flex; justify-content: space-between; font-size: 0.75rem; margin-bottom: 0.25rem;">
+                <span>${policy.upvotes || 0} up</span>
+                <span>${policy.downvotes || 0} down</span>
+              </div>
+              <div style="width: 100%; height: 6px; background: var(--muted); border-radius: 9999px; overflow: hidden; border: 1px solid var(--border);">
+                <div style="height: 100%; background: var(--foreground); width: ${supportPercentage}%; transition: width 0.3s;"></div>
+              </div>
+            </div>
+            <small style="color: var(--muted-foreground);">
+              ${supportPercentage}% support (${totalVotes} total)
+            </small>
+          ` : `
+            <small style="color: var(--muted-foreground);">No votes</small>
+          `}
+        </div>
+      </td>
+      <td>
+        <small>${new Date(policy.created_at).toLocaleDateString()}</small>
+      </td>
+      <td>
+        <div style="display: flex; gap: 0.5rem; flex-wrap: wrap;">
+          ${renderActionButtons(policy)}
+        </div>
+      </td>
+    </tr>
+  `;
+}
+
+async function loadMorePolicies() {
+  if (!policiesCursor) return;
+
+  const params = new URLSearchParams({ cursor: policiesCursor });
+  if (statusFilter.value) params.append('status', statusFilter.value);
+
+  try {
+    const data = await apiRequest(`/admin/policies?${params.toString()}`);
+    loadedPolicies = loadedPolicies.concat(data.policies);
+    policiesCursor = data.next_cursor;
+
+    policiesContainer.querySelector('tbody').insertAdjacentHTML('beforeend', data.policies.map(renderPolicyRow).join(''));
+    document.getElementById('load-more-policies').outerHTML = renderLoadMorePolicies();
+  } catch (error) {
+    alertContainer.innerHTML = `<div class="alert alert-error">${error.message}</div>`;
+  }
+}
+
+function renderLoadMorePolicies() {
+  return `
+    <div id="load-more-policies" style="text-align: center; margin-top: 1rem;">
+      ${policiesCursor ? '<button class="btn btn-secondary btn-sm" onclick="loadMorePolicies()">Load More</button>' : ''}
+    </div>
+  `;
+}
+
 function renderActionButtons(policy) {
   let buttons = '';
   
   buttons += `<button class="btn btn-secondary btn-sm" onclick="openEditModal('${policy.id}')">Edit</button>`;
   
-  if (policy.status !== 'approved') {
-    buttons += `<button class="btn btn-success btn-sm" onclick="updateStatus('${policy.id}', 'approved')">Approve</button>`;
-  }
-  if (policy.status !== 'in_progress' && policy.status === 'approved') {
-    buttons += `<button class="btn btn-secondary btn-sm" onclick="updateStatus('${policy.id}', 'in_progress')">In Progress</button>`;
-  }
-  if (policy.status !== 'completed' && policy.status === 'in_progress') {
-    buttons += `<button class="btn btn-success btn-sm" onclick="updateStatus('${policy.id}', 'completed')">Complete</button>`;
-  }
-  if (policy.status !== 'uncertain') {
-    buttons += `<button class="btn btn-warning btn-sm" onclick="updateStatus('${policy.id}', 'uncertain')">Uncertain</button>`;
-  }
-  if (policy.status !== 'rejected') {
-    buttons += `<button class="btn btn-danger btn-sm" onclick="updateStatus('${policy.id}', 'rejected')">Reject</button>`;
-  }
-  if (policy.status === 'approved' || policy.status === 'in_progress') {
-    buttons += `<button class="btn btn-secondary btn-sm" onclick="updateStatus('${policy.id}', 'on_hold')">Hold</button>`;
-  }
-  if (policy.status === 'approved' || policy.status === 'uncertain') {
-    buttons += `<button class="btn btn-secondary btn-sm" onclick="updateStatus('${policy.id}', 'cannot_implement')">Can't Do</button>`;
-  }
+  (policy.allowed_transitions || []).forEach(status => {
+    const action = statusActions[status];
+    if (action) {
+      buttons += `<button class="btn ${action.style} btn-sm" onclick="updateStatus('${policy.id}', '${status}')">${action.label}</button>`;
+    }
+  });
   
-  buttons += `<button class="btn btn-secondary btn-sm" onclick="addComment('${policy.id}')">Comment</button>`;
+  buttons += `<button class="btn btn-secondary btn-sm" onclick="addComment('${policy.id}')">Publish Comment</button>`;
+  buttons += `<a class="btn btn-secondary btn-sm" href="/policy/${policy.id}#feedback">Review Thread</a>`;
+  buttons += `<button class="btn btn-secondary btn-sm" onclick="showDuplicates('${policy.id}')">Duplicates</button>`;
+  buttons += `<button class="btn btn-secondary btn-sm" onclick="openSplitModal('${policy.id}')">Split</button>`;
   buttons += `<button class="btn btn-danger btn-sm" onclick="confirmDelete('${policy.id}', '${escapeHtml(policy.title)}')">Delete</button>`;
   
   return buttons;
 }
 
+async function showDuplicates(policyId) {
+  try {
+    const duplicates = await apiRequest(`/admin/policies/${policyId}/duplicates`);
+
+    if (duplicates.length === 0) {
+      alertContainer.innerHTML = '<div class="alert alert-success">No similar policies found</div>';
+      return;
+    }
+
+    alertContainer.innerHTML = `
+      <div class="alert alert-warning">
+        <strong>Similar policies</strong>
+        <ul style="margin: 0.5rem 0 0 1.25rem;">
+          ${duplicates.map(d => `
+            <li style="margin-bottom: 0.5rem;">
+              <a href="/policy/${d.id}">${escapeHtml(d.title)}</a>
+              <small>(${d.status}, ${Math.round(d.similarity * 100)}% similar, ${d.upvotes + d.downvotes} votes)</small>
+              <button class="btn btn-secondary btn-sm" onclick="mergeInto('${policyId}', '${d.id}')">Merge into this</button>
+            </li>
+          `).join('')}
+        </ul>
+      </div>
+    `;
+  } catch (error) {
+    alertContainer.innerHTML = `<div class="alert alert-error">${error.message}</div>`;
+  }
+}
+
+async function mergeInto(policyId, targetId) {
+  if (!confirm('Merge this policy into the selected one? Its votes and comments will move to the surviving policy.')) return;
+
+  try {
+    const { merged: [result] } = await apiRequest(`/admin/policies/${targetId}/merge`, {
+      method: 'POST',
+      body: JSON.stringify({ source_ids: [policyId] }),
+    });
+    alertContainer.innerHTML = `
+      <div class="alert alert-success">
+        Merged: ${result.votes_moved} votes and ${result.comments_moved} comments moved, ${result.votes_dropped} duplicate votes dropped
+      </div>
+    `;
+    loadPolicies();
+    loadStats();
+  } catch (error) {
+    alertContainer.innerHTML = `<div class="alert alert-error">${error.message}</div>`;
+  }
+}
+
 async function openEditModal(policyId) {
   try {
     const policy = await apiRequest(`/admin/policies/${policyId}`);
@@ -267,8 +351,98 @@ function togglePolicySelection(policyId, selected) {
   
   document.getElementById('bulk-delete-btn').style.display = 
     selectedPolicies.size > 0 ? 'inline-flex' : 'none';
+  document.getElementById('bulk-merge-btn').style.display =
+    selectedPolicies.size > 1 ? 'inline-flex' : 'none';
+}
+
+async function bulkMerge() {
+  const selected = loadedPolicies.filter(p => selectedPolicies.has(p.id));
+  if (selected.length < 2) return;
+
+  const choice = prompt(
+    'Merge the selected policies into which one? Enter its number:\n' +
+    selected.map((p, i) => `${i + 1}. ${p.title}`).join('\n')
+  );
+  const target = selected[parseInt(choice, 10) - 1];
+  if (!target) return;
+
+  try {
+    const result = await apiRequest(`/admin/policies/${target.id}/merge`, {
+      method: 'POST',
+      body: JSON.stringify({
+        source_ids: selected.filter(p => p.id !== target.id).map(p => p.id),
+      }),
+    });
+
+    const votes = result.merged.reduce((sum, m) => sum + m.votes_moved, 0);
+    const comments = result.merged.reduce((sum, m) => sum + m.comments_moved, 0);
+    alertContainer.innerHTML = `
+      <div class="alert alert-success">Merged ${result.merged.length} policies into "${escapeHtml(target.title)}" (${votes} votes, ${comments} comments moved)</div>
+    `;
+
+    selectedPolicies.clear();
+    loadPolicies();
+    loadStats();
+  } catch (error) {
+    alertContainer.innerHTML = `<div class="alert alert-error">${error.message}</div>`;
+  }
+}
+
+function openSplitModal(policyId) {
+  splittingPolicyId = policyId;
+  document.getElementById('split-parts').innerHTML = '';
+  addSplitPart();
+  addSplitPart();
+  document.getElementById('split-modal').style.display = 'block';
 }
 
+function addSplitPart() {
+  const container = document.getElementById('split-parts');
+  const n = container.children.length + 1;
+  const part = document.createElement('div');
+  part.className = 'split-part';
+  part.innerHTML = `
+    <div class="form-group">
+      <label>Part ${n} title</label>
+      <input type="text" class="split-title" minlength="10" maxlength="200" required>
+    </div>
+    <div class="form-group">
+      <label>Part ${n} description</label>
+      <textarea class="split-description" rows="3" minlength="50" maxlength="2000" required></textarea>
+    </div>
+  `;
+  container.appendChild(part);
+}
+
+function closeSplitModal() {
+  document.getElementById('split-modal').style.display = 'none';
+  splittingPolicyId = null;
+}
+
+document.getElementById('split-policy-form').addEventListener('submit', async (e) => {
+  e.preventDefault();
+
+  const parts = Array.from(document.querySelectorAll('#split-parts .split-part')).map(part => ({
+    title: part.querySelector('.split-title').value.trim(),
+    description: part.querySelector('.split-description').value.trim(),
+  }));
+
+  try {
+    const result = await apiRequest(`/admin/policies/${splittingPolicyId}/split`, {
+      method: 'POST',
+      body: JSON.stringify({ parts }),
+    });
+    alertContainer.innerHTML = `
+      <div class="alert alert-success">Split into ${result.part_ids.length} pending policies</div>
+    `;
+    closeSplitModal();
+    loadPolicies();
+    loadStats();
+  } catch (error) {
+    alertContainer.innerHTML = `<div class="alert alert-error">${error.message}</div>`;
+  }
+});
+
 async function bulkDelete() {
   if (selectedPolicies.size === 0) return;
   
@@ -453,7 +627,8 @@ async function loadAuditLog() {
   container.innerHTML = '<div class="loading">Loading audit log...</div>';
 
   try {
-    const logs = await apiRequest('/admin/audit-log?limit=100');
+    const data = await apiRequest('/admin/audit-log?limit=100');
+    const logs = data.entries;
 
     if (logs.length === 0) {
       container.innerHTML = '<div class="empty-state"><p>No audit logs</p></div>';
@@ -503,7 +678,7 @@ function addComment(policyId) {
   currentPolicyId = policyId;
   currentAction = 'comment';
   
-  document.getElementById('modal-title').textContent = 'Add Comment';
+  document.getElementById('modal-title').textContent = 'Publish Comment (leave empty to unpublish)';
   document.getElementById('admin-comment').value = '';
   document.getElementById('modal').style.display = 'block';
 }
@@ -612,4 +787,18 @@ function escapeHtml(text) {
 statusFilter.addEventListener('change', loadPolicies);
 
 loadStats();
-loadPolicies();
\ No newline at end of file
+loadPolicies();
+
+// Submissions and reviews by other admins refresh the list; bursts such as
+// bulk actions are folded into one reload.
+let moderationReload = null;
+wsSubscribe('moderation');
+onWebSocketMessage(data => {
+  if (['policy_created', 'policy_update', 'policy_deleted', 'category_changed', 'resync'].includes(data.type)) {
+    clearTimeout(moderationReload);
+    moderationReload = setTimeout(() => {
+      loadStats();
+      loadPolicies();
+    }, 500);
+  }
+});
\ No newline at end of file
@@ -11,6 +11,8 @@ if (isSuperuser()) {
 const policiesContainer = document.getElementById('policies-container');
 const alertContainer = document.getElementById('alert-container');
 let searchTimeout;
+let policyParams = null;
+let nextCursor = null;
 
 async function loadCategories() {
   try {
@@ -32,15 +34,24 @@ async function loadPolicies() {
   const search = document.getElementById('search-input')?.value || '';
   const category = document.getElementById('category-filter')?.value || '';
   const status = document.getElementById('status-filter')?.value || '';
-  const sort = document.getElementById('sort-filter')?.value || 'newest';
+  const sort = document.getElementById('sort-filter')?.value || '';
 
-  const params = new URLSearchParams({ sort });
-  if (search) params.append('search', search);
+  // Without an explicit sort the server ranks search results by relevance
+  // and otherwise lists the newest first.
+  const params = new URLSearchParams();
+  if (sort) params.append('sort', sort);
+  if (search) {
+    params.append('search', search);
+    params.append('lang', searchLanguage());
+  }
   if (category) params.append('category', category);
   if (status) params.append('status', status);
+  policyParams = params;
 
   try {
-    const policies = await apiRequest(`/policies?${params.toString()}`);
+    const data = await apiRequest(`/policies?${params.toString()}`);
+    const policies = data.policies;
+    nextCursor = data.next_cursor;
 
     if (policies.length === 0) {
       policiesContainer.innerHTML = `
@@ -53,7 +64,7 @@ async function loadPolicies() {
       return;
     }
 
-    policiesContainer.innerHTML = policies.map(renderPolicyCard).join('');
+    policiesContainer.innerHTML = policies.map(renderPolicyCard).join('') + renderLoadMore();
     attachVoteHandlers();
 
   } catch (error) {
@@ -63,16 +74,56 @@ async function loadPolicies() {
   }
 }
 
+// loadMorePolicies appends the next page of the current listing. Vote
+// handlers are delegated from the container, so new cards need no wiring.
+async function loadMorePolicies() {
+  if (!nextCursor || !policyParams) return;
+
+  const params = new URLSearchParams(policyParams);
+  params.append('cursor', nextCursor);
+
+  const button = document.getElementById('load-more-btn');
+  button.disabled = true;
+  button.textContent = 'Loading...';
+
+  try {
+    const data = await apiRequest(`/policies?${params.toString()}`);
+    nextCursor = data.next_cursor;
+
+    document.getElementById('load-more').remove();
+    policiesContainer.insertAdjacentHTML('beforeend', data.policies.map(renderPolicyCard).join('') + renderLoadMore());
+  } catch (error) {
+    button.disabled = false;
+    button.textContent = 'Load More';
+    showTempAlert(error.message, 'error');
+  }
+}
+
+function renderLoadMore() {
+  if (!nextCursor) return '';
+  return `
+    <div id="load-more" style="text-align: center; margin-top: 1rem;">
+      <button class="btn btn-secondary" id="load-more-btn" onclick="loadMorePolicies()">Load More</button>
+    </div>
+  `;
+}
+
+function searchLanguage() {
+  return (navigator.language || '').toLowerCase().startsWith('ro') ? 'ro' : 'en';
+}
+
 function renderPolicyCard(policy) {
-  const deviceHasVoted = !!policy.current_user_vote;
+  const deviceHasVoted = !!policy.has_voted;
   const totalVotes = (policy.upvotes || 0) + (policy.downvotes || 0);
   const supportPercentage = totalVotes > 0 ? ((policy.upvotes || 0) / totalVotes * 100).toFixed(1) : 0;
   
+  // Search highlights come from the server already HTML-escaped, with
+  // <mark> as the only markup.
   return `
     <div class="card" id="policy-card-${policy.id}" data-policy-id="${policy.id}">
       <div class="card-header">
         <div style="flex: 1;">
-          <h3 class="card-title">${escapeHtml(policy.title)}</h3>
+          <h3 class="card-title">${policy.title_highlight || escapeHtml(policy.title)}</h3>
           ${policy.category_name ? `<small style="color: var(--muted-foreground);">${escapeHtml(policy.category_name)}</small>` : ''}
         </div>
         <div style="display: flex; gap: 0.5rem; align-items: center;">
@@ -86,7 +137,8 @@ function renderPolicyCard(policy) {
         </div>
       </div>
       
-      <p>${escapeHtml(policy.description)}</p>
+      <p>${policy.snippet || escapeHtml(policy.description)}</p>
+      ${policy.comment_snippet ? `<div class="info-box"><strong>Comment:</strong> ${policy.comment_snippet}</div>` : ''}
       
       ${policy.admin_comment ? `<div class="info-box"><strong>Admin:</strong> ${escapeHtml(policy.admin_comment)}</div>` : ''}
       
@@ -265,4 +317,17 @@ function fallbackCopyToClipboard(text) {
 
 loadCategories();
 loadPolicies();
-setupFilterHandlers();
\ No newline at end of file
+setupFilterHandlers();
+
+// One subscription covers tallies and status changes for every public
+// policy, however many cards are loaded. Policies that become visible are
+// announced rather than inserted, so the list does not shift under a
+// student who is reading or voting.
+wsSubscribe('policies');
+onWebSocketMessage(data => {
+  if (data.type === 'policy_published') {
+    showTempAlert('New policies are available. <a href="#" onclick="loadPolicies(); return false;">Refresh</a>', 'success', 10000);
+  } else if (data.type === 'resync') {
+    loadPolicies();
+  }
+});
\ No newline at end of file
@@ -17,6 +17,8 @@ const DRAFT_KEY = 'policy_draft';
 const AUTO_SAVE_INTERVAL = 30000;
 let autoSaveTimer = null;
 let draftLoaded = false;
+let editingPolicyId = null;
+let mySubmissions = [];
 
 async function loadCategories() {
   try {
@@ -36,6 +38,8 @@ async function loadCategories() {
 }
 
 function saveDraft() {
+  if (editingPolicyId) return;
+
   const draft = {
     title: titleInput.value.trim(),
     description: descriptionInput.value.trim(),
@@ -144,6 +148,59 @@ function updateCharacterCounters() {
   descriptionInput.dispatchEvent(new Event('input'));
 }
 
+let similarTimer = null;
+
+function renderSimilarPolicies(policies, heading) {
+  const container = document.getElementById('similar-policies');
+  if (policies.length === 0) {
+    container.innerHTML = '';
+    return;
+  }
+
+  container.innerHTML = `
+    <div class="alert alert-warning">
+      <strong>${heading}</strong>
+      <ul style="margin: 0.5rem 0 0 1.25rem;">
+        ${policies.map(p => `
+          <li>
+            <a href="/policy/${p.id}">${escapeHtml(p.title)}</a>
+            <small>(${p.status}, ${p.upvotes} up, ${p.downvotes} down)</small>
+          </li>
+        `).join('')}
+      </ul>
+    </div>
+  `;
+}
+
+async function checkSimilarPolicies() {
+  if (editingPolicyId) return;
+
+  const title = titleInput.value.trim();
+  const description = descriptionInput.value.trim();
+  if (title.length < 10) {
+    renderSimilarPolicies([], '');
+    return;
+  }
+
+  try {
+    const params = new URLSearchParams({ title, description });
+    const similar = await apiRequest(`/policies/similar?${params}`);
+    renderSimilarPolicies(similar, 'Similar policies already exist. Consider voting on one of them instead:');
+  } catch (error) {
+    console.error('Failed to check similar policies:', error);
+  }
+}
+
+function scheduleSimilarCheck() {
+  clearTimeout(similarTimer);
+  similarTimer = setTimeout(checkSimilarPolicies, 800);
+}
+
+titleInput.addEventListener('input', scheduleSimilarCheck);
+descriptionInput.addEventListener('input', scheduleSimilarCheck);
+
+let ignoreDuplicates = false;
+
 form.addEventListener('submit', async (e) => {
   e.preventDefault();
 
@@ -154,6 +211,30 @@ form.addEventListener('submit', async (e) => {
 
   alertContainer.innerHTML = '';
   submitBtn.disabled = true;
+
+  if (editingPolicyId) {
+    submitBtn.textContent = 'Saving...';
+    try {
+      const data = await apiRequest(`/policies/${editingPolicyId}`, {
+        method: 'PUT',
+        body: JSON.stringify({
+          title,
+          description,
+          category_id: category,
+          note: document.getElementById('resubmit-note').value.trim(),
+        }),
+      });
+      alertContainer.innerHTML = `<div class="alert alert-success">${data.message}</div>`;
+      stopEditing();
+      loadMySubmissions();
+    } catch (error) {
+      alertContainer.innerHTML = `<div class="alert alert-error">${error.message}</div>`;
+      submitBtn.disabled = false;
+      submitBtn.textContent = 'Save Changes';
+    }
+    return;
+  }
+
   submitBtn.textContent = 'Submitting...';
 
   try {
@@ -162,7 +243,8 @@ form.addEventListener('submit', async (e) => {
       body: JSON.stringify({ 
         title, 
         description,
-        category_id: category
+        category_id: category,
+        ignore_duplicates: ignoreDuplicates
       }),
     });
 
@@ -173,6 +255,8 @@ form.addEventListener('submit', async (e) => {
     `;
 
     form.reset();
+    ignoreDuplicates = false;
+    renderSimilarPolicies([], '');
     document.getElementById('title-counter').textContent = '0/200';
     document.getElementById('description-counter').textContent = '0/2000';
     clearDraft();
@@ -193,6 +277,12 @@ form.addEventListener('submit', async (e) => {
     }, 2000);
 
   } catch (error) {
+    if (error.status === 409 && error.data && error.data.duplicates) {
+      renderSimilarPolicies(error.data.duplicates, 'This looks like an existing policy or one you already submitted. Vote on it or wait for review instead, or submit anyway if yours is different.');
+      ignoreDuplicates = true;
+      return;
+    }
+
     alertContainer.innerHTML = `
       <div class="alert alert-error">${error.message}</div>
     `;
@@ -202,13 +292,122 @@ form.addEventListener('submit', async (e) => {
     }
   } finally {
     submitBtn.disabled = false;
-    submitBtn.textContent = 'Submit Policy';
+    submitBtn.textContent = ignoreDuplicates ? 'Submit Anyway' : 'Submit Policy';
   }
 });
 
+async function loadMySubmissions() {
+  const container = document.getElementById('my-submissions');
+
+  try {
+    const policies = await apiRequest('/policies/mine');
+
+    if (policies.length === 0) {
+      container.innerHTML = '<p class="help-text">You have not submitted any policies yet.</p>';
+      return;
+    }
+
+    container.innerHTML = policies.map(policy => `
+      <div class="submission-item">
+        <div style="display: flex; justify-content: space-between; align-items: center; gap: 1rem;">
+          <a href="/policy/${policy.id}"><strong>${escapeHtml(policy.title)}</strong></a>
+          <span class="badge badge-${policy.status}">${policy.status}</span>
+        </div>
+        <small class="help-text">
+          Submitted ${new Date(policy.created_at).toLocaleDateString()}
+          ${policy.category_name ? ` · ${escapeHtml(policy.category_name)}` : ''}
+          · ${policy.upvotes} up, ${policy.downvotes} down
+        </small>
+        ${policy.admin_comment ? `<p style="margin-top: 0.5rem;"><strong>Admin:</strong> ${escapeHtml(policy.admin_comment)}</p>` : ''}
+        ${policy.changes_requested ? `<div class="alert alert-warning" style="margin-top: 0.5rem;">A reviewer has asked for changes. See the <a href="/policy/${policy.id}#feedback">review thread</a>.</div>` : ''}
+        ${!policy.changes_requested && policy.feedback_count > 0 ? `<small><a href="/policy/${policy.id}#feedback">Review thread (${policy.feedback_count})</a></small>` : ''}
+        ${policy.can_edit ? `
+          <div style="display: flex; gap: 0.5rem; margin-top: 0.5rem;">
+            <button class="btn btn-secondary btn-sm" onclick="editSubmission('${policy.id}')">Edit</button>
+            <button class="btn btn-danger btn-sm" onclick="withdrawSubmission('${policy.id}')">Withdraw</button>
+          </div>
+        ` : ''}
+      </div>
+    `).join('');
+
+    mySubmissions = policies;
+  } catch (error) {
+    container.innerHTML = `<div class="alert alert-error">${error.message}</div>`;
+  }
+}
+
+window.editSubmission = function(policyId) {
+  const policy = mySubmissions.find(p => p.id === policyId);
+  if (!policy) return;
+
+  stopAutoSave();
+  editingPolicyId = policyId;
+  titleInput.value = policy.title;
+  descriptionInput.value = policy.description;
+  categorySelect.value = policy.category_id || '';
+  updateCharacterCounters();
+
+  document.getElementById('resubmit-note').value = '';
+  document.getElementById('note-group').style.display = 'block';
+  form.querySelector('button[type="submit"]').textContent = 'Save Changes';
+  document.getElementById('cancel-btn').textContent = 'Stop Editing';
+  alertContainer.innerHTML = '';
+  window.scrollTo({ top: 0, behavior: 'smooth' });
+};
+
+window.withdrawSubmission = async function(policyId) {
+  if (!confirm('Withdraw this submission? It will not be reviewed and cannot be restored.')) return;
+
+  try {
+    const data = await apiRequest(`/policies/${policyId}/withdraw`, { method: 'POST' });
+    alertContainer.innerHTML = `<div class="alert alert-success">${data.message}</div>`;
+    if (editingPolicyId === policyId) {
+      stopEditing();
+    }
+    loadMySubmissions();
+  } catch (error) {
+    alertContainer.innerHTML = `<div class="alert alert-error">${error.message}</div>`;
+  }
+};
+
+function stopEditing() {
+  editingPolicyId = null;
+  form.reset();
+  document.getElementById('note-group').style.display = 'none';
+  updateCharacterCounters();
+
+  const submitBtn = form.querySelector('button[type="submit"]');
+  submitBtn.disabled = false;
+  submitBtn.textContent = 'Submit';
+  document.getElementById('cancel-btn').textContent = 'Cancel';
+  startAutoSave();
+}
+
+document.getElementById('cancel-btn').addEventListener('click', (e) => {
+  if (!editingPolicyId) return;
+  e.preventDefault();
+  stopEditing();
+});
+
+function escapeHtml(text) {
+  if (text === null || typeof text === 'undefined') return '';
+  const div = document.createElement('div');
+  div.textContent = text;
+  return div.innerHTML;
+}
+
 loadCategories();
 loadDraft();
 startAutoSave();
+loadMySubmissions();
+
+// Reviews and votes on the student's own policies refresh their list live.
+wsSubscribe('mine');
+onWebSocketMessage(data => {
+  if (data.type === 'policy_update' || data.type === 'resync') {
+    loadMySubmissions();
+  }
+});
 
 window.addEventListener('beforeunload', () => {
   stopAutoSave();
@@ -7,9 +7,24 @@ const editForm = document.getElementById('edit-user-form');
 
 let userToDelete = null;
 
+// fetchAllUsers follows next_cursor so the management table lists every
+// account rather than only the first page.
+async function fetchAllUsers() {
+  let users = [];
+  let cursor = null;
+  do {
+    const params = new URLSearchParams({ limit: 100 });
+    if (cursor) params.append('cursor', cursor);
+    const data = await apiRequest(`/superuser/users?${params.toString()}`);
+    users = users.concat(data.users);
+    cursor = data.next_cursor;
+  } while (cursor);
+  return users;
+}
+
 async function loadUsers() {
   try {
-    const users = await apiRequest('/superuser/users');
+    const users = await fetchAllUsers();
 
     if (users.length === 0) {
       usersContainer.innerHTML = `
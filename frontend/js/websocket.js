@@ -2,20 +2,65 @@ let ws = null;
 let reconnectAttempts = 0;
 const MAX_RECONNECT_ATTEMPTS = 5;
 
+// Networks that block WebSocket upgrades get the same messages over
+// Server-Sent Events. We switch once a socket has failed to open twice.
+let wsOpened = false;
+let eventSource = null;
+let useEventStream = false;
+let lastEventId = '';
+let eventStreamRestart = null;
+
+// Topics this page follows, resent after every reconnect. Pages call
+// wsSubscribe('policy:<id>'), 'category:<id>', 'mine' or 'moderation'.
+const wsTopics = new Set();
+const wsListeners = [];
+
+function wsSubscribe(topic) {
+  wsTopics.add(topic);
+  if (useEventStream) {
+    restartEventStream();
+  } else {
+    sendWebSocketCommand('subscribe', topic);
+  }
+}
+
+function wsUnsubscribe(topic) {
+  wsTopics.delete(topic);
+  if (useEventStream) {
+    restartEventStream();
+  } else {
+    sendWebSocketCommand('unsubscribe', topic);
+  }
+}
+
+// onWebSocketMessage registers a page-specific handler, called for every
+// message after the built-in card updates.
+function onWebSocketMessage(listener) {
+  wsListeners.push(listener);
+}
+
+function sendWebSocketCommand(action, topic) {
+  if (ws && ws.readyState === WebSocket.OPEN) {
+    ws.send(JSON.stringify({ action, topic }));
+  }
+}
+
 function connectWebSocket() {
   if (!isAuthenticated()) {
     return;
   }
 
   const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
-  const wsUrl = `${protocol}//${window.location.host}/ws`;
+  const wsUrl = `${protocol}//${window.location.host}/ws?token=${encodeURIComponent(getAuthToken())}`;
 
   try {
     ws = new WebSocket(wsUrl);
 
     ws.onopen = () => {
       console.log('WebSocket connected');
+      wsOpened = true;
       reconnectAttempts = 0;
+      wsTopics.forEach(topic => sendWebSocketCommand('subscribe', topic));
     };
 
     ws.onmessage = (event) => {
@@ -33,7 +78,14 @@ function connectWebSocket() {
 
     ws.onclose = () => {
       console.log('WebSocket disconnected');
-      
+
+      if (!wsOpened && reconnectAttempts >= 1) {
+        console.log('WebSocket unavailable, falling back to event stream');
+        useEventStream = true;
+        connectEventStream();
+        return;
+      }
+
       if (reconnectAttempts < MAX_RECONNECT_ATTEMPTS) {
         reconnectAttempts++;
         setTimeout(() => {
@@ -47,12 +99,71 @@ function connectWebSocket() {
   }
 }
 
+// connectEventStream opens the Server-Sent Events stream for the current
+// topics. EventSource reconnects by itself and resends the last event ID;
+// when topics change we reopen it and pass that ID along ourselves.
+function connectEventStream() {
+  if (!isAuthenticated()) {
+    return;
+  }
+
+  if (eventSource) {
+    eventSource.close();
+  }
+
+  const params = new URLSearchParams({
+    token: getAuthToken(),
+    topics: Array.from(wsTopics).join(',')
+  });
+  if (lastEventId) {
+    params.set('last_event_id', lastEventId);
+  }
+
+  eventSource = new EventSource(`/api/v1/events?${params}`);
+
+  eventSource.onopen = () => {
+    console.log('Event stream connected');
+  };
+
+  eventSource.onmessage = (event) => {
+    if (event.lastEventId) {
+      lastEventId = event.lastEventId;
+    }
+    try {
+      handleWebSocketMessage(JSON.parse(event.data));
+    } catch (e) {
+      console.error('Failed to parse event stream message:', e);
+    }
+  };
+
+  eventSource.onerror = () => {
+    if (eventSource.readyState === EventSource.CLOSED) {
+      console.error('Event stream closed');
+    }
+  };
+}
+
+// Subscriptions usually change in bursts, e.g. once per card on a page,
+// so restarts are batched.
+function restartEventStream() {
+  clearTimeout(eventStreamRestart);
+  eventStreamRestart = setTimeout(connectEventStream, 0);
+}
+
 function handleWebSocketMessage(data) {
   if (data.type === 'vote_update') {
     updatePolicyVotes(data.policy_id, data.data.upvotes, data.data.downvotes);
   } else if (data.type === 'policy_update') {
     updatePolicyStatus(data.policy_id, data.data.status);
+  } else if (data.type === 'policy_deleted') {
+    removePolicyCard(data.policy_id);
+  } else if (data.type === 'error') {
+    // A refused topic is forgotten so reconnects do not keep asking for it.
+    console.error('WebSocket subscription error:', data.topic, data.data.error);
+    if (data.topic) wsTopics.delete(data.topic);
   }
+
+  wsListeners.forEach(listener => listener(data));
 }
 
 function updatePolicyVotes(policyId, upvotes, downvotes) {
@@ -85,6 +196,11 @@ function updatePolicyVotes(policyId, upvotes, downvotes) {
   }
 }
 
+function removePolicyCard(policyId) {
+  const card = document.querySelector(`[data-policy-id="${policyId}"]`);
+  if (card) card.remove();
+}
+
 function updatePolicyStatus(policyId, status) {
   const card = document.querySelector(`[data-policy-id="${policyId}"]`);
   if (!card) return;